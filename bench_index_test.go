@@ -0,0 +1,79 @@
+package goquery
+
+import (
+	"testing"
+)
+
+func benchmarkFind(b *testing.B, doc *Document, selector string, index bool) {
+	var n int
+
+	b.StopTimer()
+	if index {
+		doc.BuildIndex()
+		defer doc.DropIndex()
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		n = doc.Find(selector).Length()
+	}
+	if n == 0 {
+		b.Fatalf("want matches for %s", selector)
+	}
+}
+
+func BenchmarkFindID(b *testing.B) {
+	benchmarkFind(b, DocW(), "#toc", false)
+}
+
+func BenchmarkFindIDIndexed(b *testing.B) {
+	benchmarkFind(b, DocW(), "#toc", true)
+}
+
+func BenchmarkFindClass(b *testing.B) {
+	benchmarkFind(b, DocW(), ".toclevel-1", false)
+}
+
+func BenchmarkFindClassIndexed(b *testing.B) {
+	benchmarkFind(b, DocW(), ".toclevel-1", true)
+}
+
+func BenchmarkFindTag(b *testing.B) {
+	benchmarkFind(b, DocW(), "a", false)
+}
+
+func BenchmarkFindTagIndexed(b *testing.B) {
+	benchmarkFind(b, DocW(), "a", true)
+}
+
+func BenchmarkFindClassMetalReview(b *testing.B) {
+	benchmarkFind(b, loadDoc("metalreview.html"), ".slider-item", false)
+}
+
+func BenchmarkFindClassMetalReviewIndexed(b *testing.B) {
+	benchmarkFind(b, loadDoc("metalreview.html"), ".slider-item", true)
+}
+
+func BenchmarkFindTagMetalReview(b *testing.B) {
+	benchmarkFind(b, loadDoc("metalreview.html"), "img", false)
+}
+
+func BenchmarkFindTagMetalReviewIndexed(b *testing.B) {
+	benchmarkFind(b, loadDoc("metalreview.html"), "img", true)
+}
+
+func BenchmarkFindNestedIndexed(b *testing.B) {
+	var n int
+
+	b.StopTimer()
+	doc := loadDoc("metalreview.html")
+	doc.BuildIndex()
+	defer doc.DropIndex()
+	sel := doc.Find(".slider-item")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		n = sel.Find("strong").Length()
+	}
+	if n == 0 {
+		b.Fatal("want matches for strong")
+	}
+}
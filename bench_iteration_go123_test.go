@@ -0,0 +1,49 @@
+//go:build go1.23
+
+package goquery
+
+import "testing"
+
+func BenchmarkAll(b *testing.B) {
+	var tmp, n int
+
+	b.StopTimer()
+	sel := DocW().Find("td")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		for range sel.All() {
+			tmp++
+		}
+		if n == 0 {
+			n = tmp
+		}
+	}
+	if n != 59 {
+		b.Fatalf("want 59, got %d", n)
+	}
+}
+
+func BenchmarkFindIterFirst(b *testing.B) {
+	var n int
+
+	for i := 0; i < b.N; i++ {
+		for _, s := range DocW().FindIter("a") {
+			n = s.Length()
+			break
+		}
+	}
+	if n != 1 {
+		b.Fatalf("want 1, got %d", n)
+	}
+}
+
+func BenchmarkFindAndFirst(b *testing.B) {
+	var n int
+
+	for i := 0; i < b.N; i++ {
+		n = DocW().Find("a").First().Length()
+	}
+	if n != 1 {
+		b.Fatalf("want 1, got %d", n)
+	}
+}
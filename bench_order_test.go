@@ -0,0 +1,41 @@
+package goquery
+
+import (
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func BenchmarkSortDocumentOrder(b *testing.B) {
+	var n int
+
+	b.StopTimer()
+	nodes := DocW().Find("li").Nodes
+	rev := make([]*html.Node, 0, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		rev = append(rev, nodes[i])
+	}
+	sel := DocW().FindNodes(rev...)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		n = sel.SortDocumentOrder().Length()
+	}
+	if n != 373 {
+		b.Fatalf("want 373, got %d", n)
+	}
+}
+
+func BenchmarkUnion(b *testing.B) {
+	var n int
+
+	b.StopTimer()
+	sel := DocW().Find("span")
+	sel2 := DocW().Find("a")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		n = sel.Union(sel2).Length()
+	}
+	if n != 1063 {
+		b.Fatalf("want 1063, got %d", n)
+	}
+}
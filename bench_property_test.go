@@ -1,6 +1,7 @@
 package goquery
 
 import (
+	"io/ioutil"
 	"testing"
 )
 
@@ -49,3 +50,21 @@ func BenchmarkHtml(b *testing.B) {
 		sel.Html()
 	}
 }
+
+func BenchmarkWriteHtml(b *testing.B) {
+	b.StopTimer()
+	sel := DocW().Find("h2")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		sel.WriteHtml(ioutil.Discard)
+	}
+}
+
+func BenchmarkWriteOuterHtml(b *testing.B) {
+	b.StopTimer()
+	sel := DocW().Find("h2")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		sel.WriteOuterHtml(ioutil.Discard)
+	}
+}
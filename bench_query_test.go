@@ -109,3 +109,35 @@ func BenchmarkContains(b *testing.B) {
 		b.Fatal("want true")
 	}
 }
+
+func BenchmarkIsNoSelectorCache(b *testing.B) {
+	var y bool
+
+	b.StopTimer()
+	SetSelectorCacheSize(0)
+	defer SetSelectorCacheSize(DefaultSelectorCacheSize)
+	sel := DocW().Find("li")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		y = sel.Is(".toclevel-2")
+	}
+	if !y {
+		b.Fatal("want true")
+	}
+}
+
+func BenchmarkIsPositionalNoSelectorCache(b *testing.B) {
+	var y bool
+
+	b.StopTimer()
+	SetSelectorCacheSize(0)
+	defer SetSelectorCacheSize(DefaultSelectorCacheSize)
+	sel := DocW().Find("li")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		y = sel.Is("li:nth-child(2)")
+	}
+	if !y {
+		b.Fatal("want true")
+	}
+}
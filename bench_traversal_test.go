@@ -20,6 +20,39 @@ func BenchmarkFind(b *testing.B) {
 	}
 }
 
+func BenchmarkFindFirst(b *testing.B) {
+	var n int
+
+	for i := 0; i < b.N; i++ {
+		if n == 0 {
+			n = DocW().FindFirst("a").Length()
+		} else {
+			DocW().FindFirst("a")
+		}
+	}
+	if n != 1 {
+		b.Fatalf("want 1, got %d", n)
+	}
+}
+
+func BenchmarkFindNoSelectorCache(b *testing.B) {
+	var n int
+
+	SetSelectorCacheSize(0)
+	defer SetSelectorCacheSize(DefaultSelectorCacheSize)
+	for i := 0; i < b.N; i++ {
+		if n == 0 {
+			n = DocB().Find("dd").Length()
+
+		} else {
+			DocB().Find("dd")
+		}
+	}
+	if n != 41 {
+		b.Fatalf("want 41, got %d", n)
+	}
+}
+
 func BenchmarkFindWithinSelection(b *testing.B) {
 	var n int
 
@@ -149,6 +182,26 @@ func BenchmarkChildrenFiltered(b *testing.B) {
 	}
 }
 
+func BenchmarkChildrenFilteredNoSelectorCache(b *testing.B) {
+	var n int
+
+	b.StopTimer()
+	SetSelectorCacheSize(0)
+	defer SetSelectorCacheSize(DefaultSelectorCacheSize)
+	sel := DocW().Find("h3")
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if n == 0 {
+			n = sel.ChildrenFiltered(".editsection").Length()
+		} else {
+			sel.ChildrenFiltered(".editsection")
+		}
+	}
+	if n != 2 {
+		b.Fatalf("want 2, got %d", n)
+	}
+}
+
 func BenchmarkParent(b *testing.B) {
 	var n int
 
@@ -0,0 +1,104 @@
+package goquery
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultSelectorCacheSize is the default maximum number of compiled
+// selectors kept in the cache used by the methods that take a selector
+// string argument.
+const DefaultSelectorCacheSize = 256
+
+// selectorCache is the package-wide cache of compiled selector strings.
+var selectorCache = newMatcherCache(DefaultSelectorCacheSize)
+
+// SetSelectorCacheSize sets the maximum number of compiled selectors kept
+// in the cache used by the methods that take a selector string argument
+// (Find, Filter, Is, etc.). When the cache is full, the least recently used
+// selector is evicted. A size of 0 or less disables the cache, so that
+// selector strings are compiled on every call. It is safe to call this
+// function concurrently with the use of goquery.
+func SetSelectorCacheSize(n int) {
+	selectorCache.resize(n)
+}
+
+// matcherCache is a concurrency-safe LRU cache of Matchers keyed by their
+// selector string.
+type matcherCache struct {
+	mu    sync.Mutex
+	max   int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type matcherCacheEntry struct {
+	selector string
+	m        Matcher
+}
+
+func newMatcherCache(max int) *matcherCache {
+	return &matcherCache{
+		max:   max,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached Matcher for the selector string, if any.
+func (c *matcherCache) get(selector string) (Matcher, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[selector]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*matcherCacheEntry).m, true
+}
+
+// add stores the Matcher for the selector string, evicting the least
+// recently used entries if the cache is full.
+func (c *matcherCache) add(selector string, m Matcher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.max <= 0 {
+		return
+	}
+	if e, ok := c.items[selector]; ok {
+		e.Value.(*matcherCacheEntry).m = m
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[selector] = c.ll.PushFront(&matcherCacheEntry{selector, m})
+	c.evict()
+}
+
+// resize sets the maximum size of the cache, evicting entries as needed.
+func (c *matcherCache) resize(max int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.max = max
+	c.evict()
+}
+
+// len returns the number of cached Matchers.
+func (c *matcherCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// evict removes the least recently used entries until the cache fits its
+// maximum size. The lock must be held by the caller.
+func (c *matcherCache) evict() {
+	for c.ll.Len() > 0 && c.ll.Len() > c.max {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*matcherCacheEntry).selector)
+	}
+}
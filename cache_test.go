@@ -0,0 +1,94 @@
+package goquery
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMatcherCacheEviction(t *testing.T) {
+	c := newMatcherCache(2)
+	c.add("a", invalidMatcher{})
+	c.add("b", invalidMatcher{})
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	// b is now the least recently used
+	c.add("c", invalidMatcher{})
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, sel := range []string{"a", "c"} {
+		if _, ok := c.get(sel); !ok {
+			t.Errorf("expected %s to be cached", sel)
+		}
+	}
+	if n := c.len(); n != 2 {
+		t.Errorf("expected 2 cached matchers, got %d", n)
+	}
+}
+
+func TestMatcherCacheResize(t *testing.T) {
+	c := newMatcherCache(3)
+	for _, sel := range []string{"a", "b", "c"} {
+		c.add(sel, invalidMatcher{})
+	}
+	c.resize(1)
+	if n := c.len(); n != 1 {
+		t.Fatalf("expected 1 cached matcher, got %d", n)
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Error("expected the most recent matcher to be kept")
+	}
+
+	c.resize(0)
+	c.add("d", invalidMatcher{})
+	if n := c.len(); n != 0 {
+		t.Errorf("expected disabled cache to be empty, got %d", n)
+	}
+}
+
+func TestSetSelectorCacheSize(t *testing.T) {
+	defer SetSelectorCacheSize(DefaultSelectorCacheSize)
+
+	SetSelectorCacheSize(0)
+	if n := selectorCache.len(); n != 0 {
+		t.Fatalf("expected disabled cache to be empty, got %d", n)
+	}
+	assertLength(t, Doc().Find("div.row-fluid").Nodes, 9)
+	if n := selectorCache.len(); n != 0 {
+		t.Errorf("expected disabled cache to stay empty, got %d", n)
+	}
+
+	SetSelectorCacheSize(DefaultSelectorCacheSize)
+	assertLength(t, Doc().Find("div.row-fluid").Nodes, 9)
+	if _, ok := selectorCache.get("div.row-fluid"); !ok {
+		t.Error("expected selector to be cached")
+	}
+}
+
+func TestCompileCachedInvalid(t *testing.T) {
+	// the invalid selector is cached by Find, Compile must still fail
+	Doc().Find("~")
+	if _, err := Compile("~"); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestSelectorCacheConcurrent(t *testing.T) {
+	sels := []string{"div", "p", "a", ".span12", "div.row-fluid", "~"}
+	d := Doc()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sel := sels[j%len(sels)]
+				d.Find(sel)
+				Compile(sel)
+			}
+		}()
+	}
+	wg.Wait()
+}
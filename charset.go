@@ -0,0 +1,54 @@
+package goquery
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/transform"
+)
+
+// maximum number of bytes examined to determine the encoding of a document,
+// as specified by the WHATWG encoding sniffing algorithm.
+const charsetSniffLen = 1024
+
+// byte order marks, which take precedence over any other encoding
+// information and are not part of the document's content.
+var byteOrderMarks = [][]byte{
+	{0xef, 0xbb, 0xbf}, // utf-8
+	{0xfe, 0xff},       // utf-16be
+	{0xff, 0xfe},       // utf-16le
+}
+
+// newCharsetReader returns an io.Reader that transcodes the content of r to
+// UTF-8, along with the name of the encoding that was detected. The encoding
+// is determined by, in order of precedence, a byte order mark, the charset
+// parameter of the contentType (which may be empty), a <meta charset> or
+// <meta http-equiv="Content-Type"> element in the first 1024 bytes, and
+// finally a guess based on the validity of those bytes as UTF-8, defaulting
+// to windows-1252.
+func newCharsetReader(r io.Reader, contentType string) (io.Reader, string, error) {
+	br := bufio.NewReaderSize(r, charsetSniffLen)
+	preview, err := br.Peek(charsetSniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", err
+	}
+
+	e, name, _ := charset.DetermineEncoding(preview, contentType)
+	for _, bom := range byteOrderMarks {
+		if bytes.HasPrefix(preview, bom) {
+			// the decoders don't consume the byte order mark
+			if _, err := br.Discard(len(bom)); err != nil {
+				return nil, "", err
+			}
+			break
+		}
+	}
+
+	if name == "utf-8" {
+		// no transcoding required
+		return br, name, nil
+	}
+	return transform.NewReader(br, e.NewDecoder()), name, nil
+}
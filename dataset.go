@@ -0,0 +1,157 @@
+package goquery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoData is returned by DataJSON when the Selection is empty or its
+// first element has no data-* attribute for the key.
+var ErrNoData = errors.New("goquery: no data attribute for key")
+
+// Data gets the value of the data-* attribute of the first element in the
+// Selection that corresponds to key, following the name conversion rules of
+// the HTML dataset: the key is in camel case, and each uppercase ASCII
+// letter corresponds to a hyphen followed by the lowercase letter in the
+// attribute name (e.g. the key fooBar corresponds to the attribute
+// data-foo-bar). The exists return value is false if the Selection is
+// empty, if there is no such attribute, or if the key is invalid (it
+// contains a hyphen followed by a lowercase ASCII letter).
+func (s *Selection) Data(key string) (val string, exists bool) {
+	attrName, ok := dataAttrName(key)
+	if !ok || len(s.Nodes) == 0 {
+		return "", false
+	}
+	return getAttributeValue(attrName, s.Nodes[0])
+}
+
+// SetData sets the data-* attribute that corresponds to key (see Data) on
+// each element in the set of matched elements. A string value is set
+// as-is, other values are encoded as JSON (e.g. true, 42 or
+// {"a":1}), so that they can be read back with DataJSON. The elements are
+// left unchanged if the key is invalid or the value cannot be encoded. It
+// returns the Selection.
+func (s *Selection) SetData(key string, v interface{}) *Selection {
+	attrName, ok := dataAttrName(key)
+	if !ok {
+		return s
+	}
+
+	val, ok := v.(string)
+	if !ok {
+		b, e := json.Marshal(v)
+		if e != nil {
+			return s
+		}
+		val = string(b)
+	}
+	return s.SetAttr(attrName, val)
+}
+
+// RemoveData removes the data-* attribute that corresponds to key (see
+// Data) from each element in the set of matched elements. It returns the
+// Selection.
+func (s *Selection) RemoveData(key string) *Selection {
+	if attrName, ok := dataAttrName(key); ok {
+		s.RemoveAttr(attrName)
+	}
+	return s
+}
+
+// Dataset returns the data-* attributes of the first element in the
+// Selection, as a map of their keys (see Data) to their values, like the
+// DOM's dataset property. It returns nil if the Selection is empty.
+func (s *Selection) Dataset() map[string]string {
+	if len(s.Nodes) == 0 {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, a := range s.Nodes[0].Attr {
+		if a.Namespace != "" {
+			continue
+		}
+		if key, ok := datasetKey(a.Key); ok {
+			if _, dup := m[key]; !dup {
+				m[key] = a.Val
+			}
+		}
+	}
+	return m
+}
+
+// DataJSON decodes the value of the data-* attribute of the first element
+// in the Selection that corresponds to key (see Data) as JSON, and stores
+// the result in the value pointed to by v, as json.Unmarshal does. Like
+// jQuery's data(), if v points to a string or an empty interface and the
+// value is not valid JSON, the raw value is stored instead. It returns
+// ErrNoData if there is no such attribute, or the error returned by
+// json.Unmarshal.
+func (s *Selection) DataJSON(key string, v interface{}) error {
+	if _, ok := dataAttrName(key); !ok {
+		return fmt.Errorf("goquery: invalid dataset key %q", key)
+	}
+	val, ok := s.Data(key)
+	if !ok {
+		return ErrNoData
+	}
+
+	e := json.Unmarshal([]byte(val), v)
+	if e == nil {
+		return nil
+	}
+	switch p := v.(type) {
+	case *string:
+		*p = val
+	case *interface{}:
+		*p = val
+	default:
+		return e
+	}
+	return nil
+}
+
+// dataAttrName returns the name of the data-* attribute that corresponds to
+// the dataset key, or false if the key is invalid.
+func dataAttrName(key string) (string, bool) {
+	var buf strings.Builder
+	buf.WriteString("data-")
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c == '-' && i+1 < len(key) && isASCIILower(key[i+1]):
+			return "", false
+		case 'A' <= c && c <= 'Z':
+			buf.WriteByte('-')
+			buf.WriteByte(c + 'a' - 'A')
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String(), true
+}
+
+// datasetKey returns the dataset key that corresponds to the attribute
+// attrName, or false if it is not a data-* attribute with such a key.
+func datasetKey(attrName string) (string, bool) {
+	if !strings.HasPrefix(attrName, "data-") || strings.ToLower(attrName) != attrName {
+		return "", false
+	}
+
+	name := attrName[len("data-"):]
+	var buf strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '-' && i+1 < len(name) && isASCIILower(name[i+1]) {
+			i++
+			c = name[i] - ('a' - 'A')
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), true
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
@@ -0,0 +1,100 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestData(t *testing.T) {
+	doc := loadString(t, `<div data-foo-bar="1" data-x="{&#34;a&#34;:[1,2]}" data--b="dash" data-plain="hello" data-flag="true" title="t"></div>`)
+	sel := doc.Find("div")
+
+	if v, ok := sel.Data("fooBar"); !ok || v != "1" {
+		t.Errorf("want 1, got %q (%v)", v, ok)
+	}
+	if _, ok := sel.Data("foo-bar"); ok {
+		t.Error("expected invalid key foo-bar not to exist")
+	}
+	if v, ok := sel.Data("B"); !ok || v != "dash" {
+		t.Errorf("want dash, got %q (%v)", v, ok)
+	}
+	if _, ok := doc.Find("p").Data("fooBar"); ok {
+		t.Error("expected no data for an empty selection")
+	}
+
+	want := map[string]string{
+		"fooBar": "1",
+		"x":      `{"a":[1,2]}`,
+		"B":      "dash",
+		"plain":  "hello",
+		"flag":   "true",
+	}
+	if got := sel.Dataset(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestDataJSON(t *testing.T) {
+	doc := loadString(t, `<div data-x="{&#34;a&#34;:[1,2]}" data-plain="hello" data-flag="true" data-n="42"></div>`)
+	sel := doc.Find("div")
+
+	var x struct{ A []int }
+	if err := sel.DataJSON("x", &x); err != nil || !reflect.DeepEqual(x.A, []int{1, 2}) {
+		t.Errorf("unexpected result %v, %v", x, err)
+	}
+	var flag bool
+	if err := sel.DataJSON("flag", &flag); err != nil || !flag {
+		t.Errorf("unexpected result %v, %v", flag, err)
+	}
+	var s string
+	if err := sel.DataJSON("plain", &s); err != nil || s != "hello" {
+		t.Errorf("unexpected result %q, %v", s, err)
+	}
+	var i interface{}
+	if err := sel.DataJSON("n", &i); err != nil || i != 42.0 {
+		t.Errorf("unexpected result %v, %v", i, err)
+	}
+	var n int
+	if err := sel.DataJSON("plain", &n); err == nil {
+		t.Error("expected an error decoding a string as int")
+	}
+	if err := sel.DataJSON("missing", &n); err != ErrNoData {
+		t.Errorf("expected ErrNoData, got %v", err)
+	}
+	if err := sel.DataJSON("in-valid", &n); err == nil || err == ErrNoData {
+		t.Errorf("expected an invalid key error, got %v", err)
+	}
+}
+
+func TestSetData(t *testing.T) {
+	doc := loadString(t, `<div></div><div data-old="1"></div>`)
+	sel := doc.Find("div")
+
+	sel.SetData("fooBar", "baz").SetData("obj", map[string]int{"a": 1}).SetData("n", 3).SetData("bad-key", "x")
+	for i := range sel.Nodes {
+		s := sel.Eq(i)
+		if v, _ := s.Attr("data-foo-bar"); v != "baz" {
+			t.Errorf("%d: want baz, got %q", i, v)
+		}
+		if v, _ := s.Attr("data-obj"); v != `{"a":1}` {
+			t.Errorf("%d: want JSON object, got %q", i, v)
+		}
+		var n int
+		if err := s.DataJSON("n", &n); err != nil || n != 3 {
+			t.Errorf("%d: want 3, got %d, %v", i, n, err)
+		}
+		if _, ok := s.Attr("data-bad-key"); ok {
+			t.Errorf("%d: expected invalid key to be ignored", i)
+		}
+	}
+
+	sel.SetData("f", func() {})
+	if _, ok := sel.Attr("data-f"); ok {
+		t.Error("expected a value that cannot be encoded to be ignored")
+	}
+
+	sel.RemoveData("old")
+	if _, ok := sel.Last().Data("old"); ok {
+		t.Error("expected data-old to be removed")
+	}
+}
@@ -32,7 +32,9 @@ tree, jQuery's stateful manipulation functions (like height(), css(), detach())
 have been left off.
 
 Also, because the net/html parser requires UTF-8 encoding, so does goquery: it is
-the caller's responsibility to ensure that the source document provides UTF-8 encoded HTML.
+the caller's responsibility to ensure that the source document provides UTF-8 encoded HTML,
+or to create the document with NewDocumentFromReaderWithCharset, which detects the
+encoding and converts the document to UTF-8.
 See the repository's wiki for various options on how to do this.
 
 Syntax-wise, it is as close as possible to jQuery, with the same method names when
@@ -58,23 +60,44 @@ The three dots (...) indicate that various "overloads" are available.
     - Last()
     - Slice()
 
+* cache.go : cache of the compiled selector strings.
+    - SetSelectorCacheSize()
+
+* dataset.go : access to the data-* attributes of the elements.
+    - Data(), SetData(), RemoveData(), Dataset(), DataJSON()
+
 * expand.go : methods that expand or augment the selection's set.
     - Add...()
     - AndSelf()
-    - Union(), which is an alias for AddSelection()
+    - Union(), which is like AddSelection(), in document order
 
 * filter.go : filtering methods, that reduce the selection's set.
     - End()
     - Filter...()
     - Has...()
-    - Intersection(), which is an alias of FilterSelection()
+    - Intersection(), which is like FilterSelection(), in document order
     - Not...()
 
+* form.go : reading, filling and submitting of HTML forms.
+    - Val(), SetVal()
+    - NewForm
+    - Form.Set(), Form.Values(), Form.Request()
+
+* index.go : index of the document's elements by id, class and tag name.
+    - Document.BuildIndex(), Document.DropIndex()
+
 * iteration.go : methods to loop over the selection's nodes.
     - Each()
     - EachWithBreak()
     - Map()
 
+* iteration_go123.go : range-over-func iterators (requires Go1.23+).
+    - All(), Backward(), NodesIter()
+    - FindIter...(), ParentsIter(), NextAllIter(), PrevAllIter()
+
+* loader.go : loading of documents over HTTP.
+    - Loader
+
 * manipulation.go : methods for modifying the document
     - After...()
     - Append...()
@@ -88,19 +111,55 @@ The three dots (...) indicate that various "overloads" are available.
     - Wrap...()
     - WrapAll...()
     - WrapInner...()
+    - HtmlError
+
+* markdown.go : conversion of the selection to Markdown.
+    - Markdown
+    - MarkdownOptions
+
+* observer.go : observation of the changes made to a document.
+    - Document.Observe()
+    - MutationRecord
+
+* options.go : options to configure the creation of a Document.
+    - With...()
+
+* order.go : comparison of the positions of nodes in document order.
+    - Document.CompareDocumentPosition()
+    - SortDocumentOrder(), Uniq()
+
+* prop.go : DOM properties of the elements, reflected on their attributes.
+    - Prop(), SetProp()
 
 * property.go : methods that inspect and get the node's properties values.
     - Attr*(), RemoveAttr(), SetAttr()
     - AddClass(), HasClass(), RemoveClass(), ToggleClass()
-    - Html()
+    - Html(), WriteHtml(), WriteOuterHtml(), OuterHtmlAll()
     - Length()
     - Size(), which is an alias for Length()
-    - Text()
+    - Text(), InnerText(), TextWithOptions()
 
 * query.go : methods that query, or reflect, a node's identity.
     - Contains()
     - Is...()
 
+* render.go : configurable rendering of the selection as HTML.
+    - Renderer
+
+* sanitize.go : allowlist-based sanitization of the selection's content.
+    - SanitizePolicy, DefaultSanitizePolicy()
+
+* style.go : parsing and editing of the elements' inline style.
+    - Css(), SetCss(), RemoveCss(), Styles()
+
+* table.go : extraction of the content of tables.
+    - NewTable
+    - Table.Keys(), Table.Records(), Table.WriteCSV(), Table.WriteJSON()
+
+* transaction.go : undoable changes to a document.
+    - Document.Begin()
+    - Tx.Commit(), Tx.Rollback(), Tx.Savepoint(), Tx.RollbackTo()
+
 * traversal.go : methods to traverse the HTML document tree.
     - Children...()
     - Contents()
@@ -114,6 +173,16 @@ The three dots (...) indicate that various "overloads" are available.
     - Document
     - Selection
     - Matcher
+    - SelectorError
+    - Compile(), MustCompile()
+
+* unmarshal.go : extraction of values into structs based on struct tags.
+    - Unmarshal
+    - Unmarshaler
+
+* urls.go : resolution of the document's relative URLs.
+    - AbsAttr()
+    - Document.AbsURL(), Document.AbsolutizeURLs(), Document.BaseURL()
 
 * utilities.go : definition of helper functions (and not methods on a *Selection)
 that are not part of jQuery, but are useful to goquery.
@@ -1,11 +1,13 @@
 package goquery_test
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -80,3 +82,20 @@ func ExampleNewDocumentFromReader_string() {
 
 	// Output: Header
 }
+
+// This example shows how to use a Loader to load a document over HTTP.
+func ExampleLoader() {
+	l := &goquery.Loader{
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		MaxBytes:  10 << 20,
+		UserAgent: "my-scraper/1.0",
+	}
+	doc, err := l.Load(context.Background(), "http://metalsucks.net")
+	if err != nil {
+		// err may be a *goquery.StatusError, a *goquery.ContentTypeError,
+		// goquery.ErrMaxBytesExceeded or an error from the HTTP client.
+		log.Fatal(err)
+	}
+	// doc.Url is the URL of the document after any redirect
+	fmt.Println(doc.Url, doc.Find("title").Text())
+}
@@ -7,7 +7,7 @@ import "golang.org/x/net/html"
 // The selector string is run in the context of the document of the current
 // Selection object.
 func (s *Selection) Add(selector string) *Selection {
-	return s.AddNodes(findWithMatcher([]*html.Node{s.document.rootNode}, compileMatcher(selector))...)
+	return s.AddNodes(findWithSelector(s.document, []*html.Node{s.document.rootNode}, selector)...)
 }
 
 // AddMatcher adds the matcher's matching nodes to those in the current
@@ -27,9 +27,13 @@ func (s *Selection) AddSelection(sel *Selection) *Selection {
 	return s.AddNodes(sel.Nodes...)
 }
 
-// Union is an alias for AddSelection.
+// Union is like AddSelection, except that the nodes of the new Selection
+// object are in document order.
 func (s *Selection) Union(sel *Selection) *Selection {
-	return s.AddSelection(sel)
+	if sel == nil {
+		return pushStack(s, sortedNodes(s.document, s.Nodes))
+	}
+	return pushStack(s, sortedNodes(s.document, appendWithoutDuplicates(s.Nodes, sel.Nodes, nil)))
 }
 
 // AddNodes adds the specified nodes to those in the
@@ -8,6 +8,16 @@ func (s *Selection) Filter(selector string) *Selection {
 	return s.FilterMatcher(compileMatcher(selector))
 }
 
+// FilterE is like Filter, except that it returns an error (a *SelectorError)
+// instead of an empty Selection if the selector string is invalid.
+func (s *Selection) FilterE(selector string) (*Selection, error) {
+	m, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.FilterMatcher(m), nil
+}
+
 // FilterMatcher reduces the set of matched elements to those that match
 // the given matcher. It returns a new Selection object for this subset
 // of matching elements.
@@ -21,6 +31,16 @@ func (s *Selection) Not(selector string) *Selection {
 	return s.NotMatcher(compileMatcher(selector))
 }
 
+// NotE is like Not, except that it returns an error (a *SelectorError)
+// instead of the unchanged Selection if the selector string is invalid.
+func (s *Selection) NotE(selector string) (*Selection, error) {
+	m, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.NotMatcher(m), nil
+}
+
 // NotMatcher removes elements from the Selection that match the given matcher.
 // It returns a new Selection object with the matching elements removed.
 func (s *Selection) NotMatcher(m Matcher) *Selection {
@@ -70,23 +90,40 @@ func (s *Selection) NotSelection(sel *Selection) *Selection {
 	return pushStack(s, winnowNodes(s, sel.Nodes, false))
 }
 
-// Intersection is an alias for FilterSelection.
+// Intersection is like FilterSelection, except that the nodes of the new
+// Selection object are in document order.
 func (s *Selection) Intersection(sel *Selection) *Selection {
-	return s.FilterSelection(sel)
+	if sel == nil {
+		return pushStack(s, winnowNodes(s, nil, true))
+	}
+	return pushStack(s, sortedNodes(s.document, winnowNodes(s, sel.Nodes, true)))
 }
 
 // Has reduces the set of matched elements to those that have a descendant
 // that matches the selector.
 // It returns a new Selection object with the matching elements.
 func (s *Selection) Has(selector string) *Selection {
-	return s.HasSelection(s.document.Find(selector))
+	return s.HasMatcher(compileMatcher(selector))
+}
+
+// HasE is like Has, except that it returns an error (a *SelectorError)
+// instead of an empty Selection if the selector string is invalid.
+func (s *Selection) HasE(selector string) (*Selection, error) {
+	m, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.HasMatcher(m), nil
 }
 
 // HasMatcher reduces the set of matched elements to those that have a descendant
-// that matches the matcher.
+// that matches the matcher. The descendants of each element are only searched
+// until a match is found.
 // It returns a new Selection object with the matching elements.
 func (s *Selection) HasMatcher(m Matcher) *Selection {
-	return s.HasSelection(s.document.FindMatcher(m))
+	return s.FilterFunction(func(_ int, sel *Selection) bool {
+		return findFirstWithMatcher(sel.Nodes[0], m) != nil
+	})
 }
 
 // HasNodes reduces the set of matched elements to those that have a
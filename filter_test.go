@@ -204,3 +204,50 @@ func TestEndToTop(t *testing.T) {
 	sel := Doc().Find("p").Has("small").End().End().End()
 	assertLength(t, sel.Nodes, 0)
 }
+
+func TestFilterE(t *testing.T) {
+	sel, err := Doc().Find(".span12").FilterE(".alert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, sel.Nodes, 1)
+
+	if _, err := Doc().Find(".span12").FilterE(""); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestNotE(t *testing.T) {
+	sel, err := Doc().Find(".span12").NotE(".alert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, sel.Nodes, 1)
+
+	if _, err := Doc().Find(".span12").NotE(""); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestHasE(t *testing.T) {
+	sel, err := Doc().Find(".container-fluid").HasE(".center-content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, sel.Nodes, 2)
+
+	if _, err := Doc().Find(".container-fluid").HasE(""); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestHasNested(t *testing.T) {
+	sel := Doc().Find("div").Has("p")
+	assertLength(t, sel.Nodes, len(Doc().Find("div").HasSelection(Doc().Find("p")).Nodes))
+}
+
+func TestHasMatcherContext(t *testing.T) {
+	// the selector is matched against the whole document, not just the subtree
+	sel := Doc().Find(".pvk-content").HasMatcher(compileMatcher("body .hero-unit p"))
+	assertLength(t, sel.Nodes, 1)
+}
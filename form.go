@@ -0,0 +1,727 @@
+package goquery
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Val gets the current value of the first element in the Selection, like
+// jQuery's val():
+//
+//   - for <input> elements, the value attribute, or "on" for checkboxes and
+//     radio buttons without one;
+//   - for <textarea> elements, their text content;
+//   - for <select> elements, the value of the first selected option, or of
+//     the first enabled option if none is selected and the select is not
+//     multiple;
+//   - for <option> elements, the value attribute, or the text of the option
+//     if there is none;
+//   - for other elements, the value attribute.
+//
+// It returns an empty string if the Selection is empty.
+func (s *Selection) Val() string {
+	if len(s.Nodes) == 0 {
+		return ""
+	}
+	n := s.Nodes[0]
+	switch n.DataAtom {
+	case atom.Textarea:
+		return newSingleSelection(n, nil).Text()
+	case atom.Select:
+		if opts := selectedOptions(n); len(opts) > 0 {
+			return optionValue(opts[0])
+		}
+		return ""
+	case atom.Option:
+		return optionValue(n)
+	case atom.Input:
+		return inputValue(n)
+	}
+	val, _ := getAttributeValue("value", n)
+	return val
+}
+
+// SetVal sets the value of each element in the set of matched elements:
+//
+//   - checkboxes and radio buttons are checked if their value (see Val) is
+//     one of vals, and unchecked otherwise. When a radio button is checked,
+//     the other radio buttons of its group are unchecked;
+//   - the options of <select> elements are selected if their value is one
+//     of vals, and unselected otherwise. Only the first matching option is
+//     selected if the select is not multiple;
+//   - the text content of <textarea> elements is set to the first value;
+//   - the value attribute of the other elements is set to the first value.
+//
+// With no value, the elements are unchecked, unselected or set to an empty
+// value. It returns the Selection.
+func (s *Selection) SetVal(vals ...string) *Selection {
+	first := ""
+	if len(vals) > 0 {
+		first = vals[0]
+	}
+
+	for _, n := range s.Nodes {
+		switch {
+		case n.DataAtom == atom.Input && isCheckable(n):
+			checked := containsString(vals, inputValue(n))
+			setBoolAttr(s.document, n, "checked", checked)
+			if checked && inputType(n) == "radio" {
+				uncheckRadioGroup(s.document, n)
+			}
+		case n.DataAtom == atom.Select:
+			multiple := hasAttr(n, "multiple")
+			found := false
+			for _, opt := range selectOptions(n) {
+				sel := containsString(vals, optionValue(opt)) && (multiple || !found)
+				found = found || sel
+				setBoolAttr(s.document, opt, "selected", sel)
+			}
+		case n.DataAtom == atom.Textarea:
+			for c := n.FirstChild; c != nil; c = n.FirstChild {
+				removeChild(s.document, n, c)
+			}
+			appendChild(s.document, n, &html.Node{Type: html.TextNode, Data: first})
+		default:
+			setAttr(s.document, n, "value", first)
+		}
+	}
+	return s
+}
+
+// FormFile is a file sent with a form, see Form.Files.
+type FormFile struct {
+	Filename    string
+	ContentType string
+	Content     io.Reader
+}
+
+// Form is an HTML form, used to compute the data submitted by the form and
+// to build the corresponding HTTP request. The values of its controls can
+// be changed with SetVal (or Set) before doing so.
+type Form struct {
+	// Selection holds the <form> element.
+	*Selection
+
+	// Files holds the files sent for the file inputs of the form, by name.
+	// They are only sent when the form is submitted as multipart/form-data,
+	// otherwise their filename is sent as value.
+	Files map[string][]FormFile
+}
+
+// NewForm returns the Form for the first element of the Selection: the
+// element itself if it is a <form>, its form owner if it is a form control,
+// otherwise its closest <form> ancestor or first <form> descendant. It
+// returns nil if there is no such form.
+func NewForm(sel *Selection) *Form {
+	if len(sel.Nodes) == 0 {
+		return nil
+	}
+	n := sel.Nodes[0]
+	var form *html.Node
+	switch {
+	case n.Type == html.ElementNode && n.DataAtom == atom.Form:
+		form = n
+	case isSubmittable(n):
+		form = formOwner(n)
+	default:
+		if form = closestForm(n); form == nil {
+			form = findFirstWithMatcher(n, formMatcher)
+		}
+	}
+	if form == nil {
+		return nil
+	}
+	return &Form{Selection: newSingleSelection(form, sel.document)}
+}
+
+var formMatcher = compileMatcher("form")
+
+// Set sets the values of the form controls named name, see SetVal. It
+// returns the Form.
+func (f *Form) Set(name string, vals ...string) *Form {
+	var controls []*html.Node
+	for _, n := range f.controls() {
+		if v, _ := getAttributeValue("name", n); v == name {
+			controls = append(controls, n)
+		}
+	}
+	pushStack(f.Selection, controls).SetVal(vals...)
+	return f
+}
+
+// Values returns the data submitted by the form, as per the HTML
+// specification's algorithm to construct the entry list: the values of the
+// enabled controls with a name, excluding unchecked checkboxes and radio
+// buttons and the buttons other than the submitter. Controls associated to
+// the form with their form attribute are included, and controls disabled
+// by a <fieldset> are excluded.
+//
+// The submitter is the button used to submit the form, it may be nil or an
+// empty Selection. It is ignored if it is not a submit button (e.g. a
+// reset button). For file inputs, the values are the filenames of the
+// Files.
+func (f *Form) Values(submitter *Selection) url.Values {
+	vals := make(url.Values)
+	for _, e := range f.entries(submitter) {
+		vals.Add(e.name, e.value)
+	}
+	return vals
+}
+
+// Request returns the HTTP request that submits the form with the submitter
+// button (which may be nil or an empty Selection), honouring the method,
+// action and enctype attributes of the form, or the formmethod,
+// formaction and formenctype attributes of the submitter. The action is
+// resolved against the document's base URL (see Document.BaseURL), an
+// empty action being the document's URL. The supported methods are GET and
+// POST, and the supported encodings application/x-www-form-urlencoded,
+// multipart/form-data and text/plain.
+func (f *Form) Request(submitter *Selection) (*http.Request, error) {
+	action := f.submitAttr(submitter, "action")
+	method := strings.ToLower(f.submitAttr(submitter, "method"))
+	enctype := strings.ToLower(f.submitAttr(submitter, "enctype"))
+
+	u, e := f.actionURL(action)
+	if e != nil {
+		return nil, e
+	}
+	entries := f.entries(submitter)
+
+	switch method {
+	case "post":
+	case "dialog":
+		return nil, fmt.Errorf("goquery: unsupported form method %q", method)
+	default:
+		// a missing or invalid method is GET
+		u.RawQuery = encodeFormEntries(entries)
+		return http.NewRequest(http.MethodGet, u.String(), nil)
+	}
+
+	var body bytes.Buffer
+	contentType := "application/x-www-form-urlencoded"
+	switch enctype {
+	case "multipart/form-data":
+		mw := multipart.NewWriter(&body)
+		if e := writeMultipart(mw, entries); e != nil {
+			return nil, e
+		}
+		contentType = mw.FormDataContentType()
+	case "text/plain":
+		for _, e := range entries {
+			body.WriteString(e.name + "=" + e.value + "\r\n")
+		}
+		contentType = "text/plain"
+	default:
+		body.WriteString(encodeFormEntries(entries))
+	}
+
+	req, e := http.NewRequest(http.MethodPost, u.String(), &body)
+	if e != nil {
+		return nil, e
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
+// submitAttr returns the value of the attribute of the form, overridden by
+// the "form"-prefixed attribute of the submitter.
+func (f *Form) submitAttr(submitter *Selection, name string) string {
+	if sub := submitterNode(submitter); sub != nil {
+		if v, ok := getAttributeValue("form"+name, sub); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	v, _ := getAttributeValue(name, f.Nodes[0])
+	return strings.TrimSpace(v)
+}
+
+// actionURL returns the URL of the form action.
+func (f *Form) actionURL(action string) (*url.URL, error) {
+	if f.document == nil {
+		return url.Parse(action)
+	}
+	if action == "" && f.document.Url != nil {
+		u := *f.document.Url
+		return &u, nil
+	}
+	return f.document.AbsURL(action)
+}
+
+// formEntry is an entry of the form data set.
+type formEntry struct {
+	name  string
+	value string
+	file  *FormFile
+}
+
+// entries returns the form data set, in tree order.
+func (f *Form) entries(submitter *Selection) []formEntry {
+	sub := submitterNode(submitter)
+
+	var entries []formEntry
+	for _, n := range f.controls() {
+		if isDisabled(n) || hasDatalistAncestor(n) {
+			continue
+		}
+		if isButton(n) && n != sub {
+			continue
+		}
+		name, _ := getAttributeValue("name", n)
+
+		typ := inputType(n)
+		if n.DataAtom == atom.Input && typ == "image" {
+			// the coordinates of the click are unknown, use 0,0
+			prefix := ""
+			if name != "" {
+				prefix = name + "."
+			}
+			entries = append(entries, formEntry{name: prefix + "x", value: "0"},
+				formEntry{name: prefix + "y", value: "0"})
+			continue
+		}
+		if name == "" {
+			continue
+		}
+
+		switch {
+		case n.DataAtom == atom.Select:
+			for _, opt := range selectedOptions(n) {
+				if !isDisabled(opt) {
+					entries = append(entries, formEntry{name: name, value: optionValue(opt)})
+				}
+			}
+		case n.DataAtom == atom.Textarea:
+			entries = append(entries, formEntry{name: name, value: normalizeNewlines(newSingleSelection(n, nil).Text())})
+		case n.DataAtom == atom.Input && isCheckable(n):
+			if hasAttr(n, "checked") {
+				entries = append(entries, formEntry{name: name, value: inputValue(n)})
+			}
+		case n.DataAtom == atom.Input && typ == "file":
+			files := f.Files[name]
+			if len(files) == 0 {
+				entries = append(entries, formEntry{name: name, file: &FormFile{ContentType: "application/octet-stream"}})
+			}
+			for i := range files {
+				entries = append(entries, formEntry{name: name, value: files[i].Filename, file: &files[i]})
+			}
+		case n.DataAtom == atom.Input && typ == "hidden" && name == "_charset_":
+			val, ok := getAttributeValue("value", n)
+			if !ok || val == "" {
+				val = "UTF-8"
+			}
+			entries = append(entries, formEntry{name: name, value: val})
+		default:
+			val, _ := getAttributeValue("value", n)
+			entries = append(entries, formEntry{name: name, value: val})
+		}
+
+		if dirname, ok := getAttributeValue("dirname", n); ok && dirname != "" &&
+			(n.DataAtom == atom.Textarea || (n.DataAtom == atom.Input && (typ == "text" || typ == "search"))) {
+			dir, _ := getAttributeValue("dir", n)
+			if dir != "rtl" {
+				dir = "ltr"
+			}
+			entries = append(entries, formEntry{name: dirname, value: dir})
+		}
+	}
+	return entries
+}
+
+// controls returns the submittable elements whose form owner is the form,
+// in tree order.
+func (f *Form) controls() []*html.Node {
+	form := f.Nodes[0]
+	var result []*html.Node
+	for _, c := range formControls(form) {
+		if c.owner == form {
+			result = append(result, c.node)
+		}
+	}
+	return result
+}
+
+// formControl is a submittable element and its form owner.
+type formControl struct {
+	node  *html.Node
+	owner *html.Node
+}
+
+// formControls returns the submittable elements of the tree of n, in tree
+// order, with their form owners (see formOwner). The tree is walked once,
+// instead of once for each element associated to a form with its form
+// attribute.
+func formControls(n *html.Node) []formControl {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+
+	// the first element with each id, and the form attribute of each
+	// element that has one
+	ids := make(map[string]*html.Node)
+	formIDs := make(map[*html.Node]string)
+
+	var controls []formControl
+	var walk func(n, form *html.Node)
+	walk = func(n, form *html.Node) {
+		if n.Type == html.ElementNode && n != root {
+			if id, ok := getAttributeValue("id", n); ok {
+				if _, dup := ids[id]; !dup {
+					ids[id] = n
+				}
+			}
+			if isSubmittable(n) {
+				if id, ok := getAttributeValue("form", n); ok {
+					formIDs[n] = id
+				}
+				controls = append(controls, formControl{node: n, owner: form})
+			}
+			if n.DataAtom == atom.Form {
+				form = n
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, form)
+		}
+	}
+	walk(root, nil)
+
+	for i, c := range controls {
+		if id, ok := formIDs[c.node]; ok {
+			controls[i].owner = nil
+			if el := ids[id]; el != nil && el.DataAtom == atom.Form {
+				controls[i].owner = el
+			}
+		}
+	}
+	return controls
+}
+
+// writeMultipart writes the entries to the multipart writer and closes it.
+func writeMultipart(mw *multipart.Writer, entries []formEntry) error {
+	for _, e := range entries {
+		if e.file == nil {
+			if err := mw.WriteField(e.name, e.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			multipartEscaper.Replace(e.name), multipartEscaper.Replace(e.file.Filename)))
+		ct := e.file.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		h.Set("Content-Type", ct)
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if e.file.Content != nil {
+			if _, err := io.Copy(w, e.file.Content); err != nil {
+				return err
+			}
+		}
+	}
+	return mw.Close()
+}
+
+// escapes the names and filenames of the multipart entries, as per the HTML
+// specification.
+var multipartEscaper = strings.NewReplacer("\n", "%0A", "\r", "%0D", `"`, "%22")
+
+// encodeFormEntries encodes the entries as application/x-www-form-urlencoded,
+// in order.
+func encodeFormEntries(entries []formEntry) string {
+	var buf strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(url.QueryEscape(e.name))
+		buf.WriteByte('=')
+		buf.WriteString(url.QueryEscape(e.value))
+	}
+	return buf.String()
+}
+
+// normalizeNewlines replaces the newlines of s by CRLF pairs.
+func normalizeNewlines(s string) string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	s = strings.Replace(s, "\r", "\n", -1)
+	return strings.Replace(s, "\n", "\r\n", -1)
+}
+
+// isSubmittable returns true if n is a submittable element.
+func isSubmittable(n *html.Node) bool {
+	if n.Type != html.ElementNode || n.Namespace != "" {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.Button, atom.Input, atom.Select, atom.Textarea:
+		return true
+	}
+	return false
+}
+
+// submitterNode returns the first node of the submitter, or nil if there is
+// none or if it is not a submit button.
+func submitterNode(submitter *Selection) *html.Node {
+	if submitter == nil || submitter.Length() == 0 || !isSubmitButton(submitter.Nodes[0]) {
+		return nil
+	}
+	return submitter.Nodes[0]
+}
+
+// isSubmitButton returns true if the element n is a submit button, that can
+// submit its form: a <button> whose type is submit (the default), or an
+// input whose type is submit or image.
+func isSubmitButton(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Button:
+		typ, _ := getAttributeValue("type", n)
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		return typ != "reset" && typ != "button"
+	case atom.Input:
+		typ := inputType(n)
+		return typ == "submit" || typ == "image"
+	}
+	return false
+}
+
+// isButton returns true if the element n is a button, that is only
+// submitted when it is the submitter.
+func isButton(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Button:
+		return true
+	case atom.Input:
+		switch inputType(n) {
+		case "submit", "image", "reset", "button":
+			return true
+		}
+	}
+	return false
+}
+
+func isCheckable(n *html.Node) bool {
+	typ := inputType(n)
+	return typ == "checkbox" || typ == "radio"
+}
+
+// inputType returns the type of the input element n, lowercased, "text" by
+// default.
+func inputType(n *html.Node) string {
+	typ, _ := getAttributeValue("type", n)
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if typ == "" {
+		typ = "text"
+	}
+	return typ
+}
+
+func inputValue(n *html.Node) string {
+	val, ok := getAttributeValue("value", n)
+	if !ok && isCheckable(n) {
+		return "on"
+	}
+	return val
+}
+
+// formOwner returns the form owner of the submittable element n: the form
+// referenced by its form attribute, or its closest form ancestor.
+func formOwner(n *html.Node) *html.Node {
+	id, ok := getAttributeValue("form", n)
+	if !ok {
+		return closestForm(n)
+	}
+
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	if el := findElementByID(root, id); el != nil && el.DataAtom == atom.Form {
+		return el
+	}
+	return nil
+}
+
+// findElementByID returns the first element with the id in the tree rooted
+// at n, in tree order.
+func findElementByID(n *html.Node, id string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			if v, ok := getAttributeValue("id", c); ok && v == id {
+				return c
+			}
+		}
+		if el := findElementByID(c, id); el != nil {
+			return el
+		}
+	}
+	return nil
+}
+
+func closestForm(n *html.Node) *html.Node {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.DataAtom == atom.Form {
+			return p
+		}
+	}
+	return nil
+}
+
+// isDisabled returns true if the control or option n is disabled, either
+// by its disabled attribute, or by a disabled <fieldset> (except in its
+// first <legend>) or <optgroup> ancestor.
+func isDisabled(n *html.Node) bool {
+	if hasAttr(n, "disabled") {
+		return true
+	}
+	child := n
+	for p := n.Parent; p != nil; child, p = p, p.Parent {
+		if p.Type != html.ElementNode {
+			continue
+		}
+		switch {
+		case p.DataAtom == atom.Fieldset && hasAttr(p, "disabled"):
+			if child.DataAtom != atom.Legend || child != firstChildElement(p, atom.Legend) {
+				return true
+			}
+		case p.DataAtom == atom.Optgroup && n.DataAtom == atom.Option && hasAttr(p, "disabled"):
+			return true
+		}
+	}
+	return false
+}
+
+func hasDatalistAncestor(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.DataAtom == atom.Datalist {
+			return true
+		}
+	}
+	return false
+}
+
+// firstChildElement returns the first child element of n of type a.
+func firstChildElement(n *html.Node, a atom.Atom) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			return c
+		}
+	}
+	return nil
+}
+
+// selectOptions returns the options of the select element n, including
+// those in <optgroup> elements.
+func selectOptions(n *html.Node) []*html.Node {
+	var opts []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Option:
+			opts = append(opts, c)
+		case atom.Optgroup:
+			opts = append(opts, childElements(c, atom.Option)...)
+		}
+	}
+	return opts
+}
+
+// selectedOptions returns the selected options of the select element n. If
+// none is selected and the select is not multiple (and has a display size
+// of 1), the first enabled option is selected, as browsers do.
+func selectedOptions(n *html.Node) []*html.Node {
+	opts := selectOptions(n)
+	var selected []*html.Node
+	for _, opt := range opts {
+		if hasAttr(opt, "selected") {
+			selected = append(selected, opt)
+		}
+	}
+
+	multiple := hasAttr(n, "multiple")
+	if !multiple && len(selected) > 1 {
+		// only the last selected option is selected
+		selected = selected[len(selected)-1:]
+	}
+	if len(selected) == 0 && !multiple {
+		size, _ := getAttributeValue("size", n)
+		if size = strings.TrimSpace(size); size == "" || size == "1" || size == "0" {
+			for _, opt := range opts {
+				if !isDisabled(opt) {
+					return []*html.Node{opt}
+				}
+			}
+		}
+	}
+	return selected
+}
+
+// optionValue returns the value of the option element n.
+func optionValue(n *html.Node) string {
+	if val, ok := getAttributeValue("value", n); ok {
+		return val
+	}
+	return strings.Join(strings.Fields(newSingleSelection(n, nil).Text()), " ")
+}
+
+// uncheckRadioGroup unchecks the radio buttons of the group of the radio
+// button n, other than n.
+func uncheckRadioGroup(d *Document, n *html.Node) {
+	name, _ := getAttributeValue("name", n)
+	if name == "" {
+		return
+	}
+	// the radio buttons of the group may be associated to the form with
+	// their form attribute, outside of it
+	controls := formControls(n)
+	var owner *html.Node
+	for _, c := range controls {
+		if c.node == n {
+			owner = c.owner
+			break
+		}
+	}
+	for _, c := range controls {
+		if c.node != n && c.owner == owner && c.node.DataAtom == atom.Input && inputType(c.node) == "radio" {
+			if v, _ := getAttributeValue("name", c.node); v == name {
+				removeAttr(d, c.node, "checked")
+			}
+		}
+	}
+}
+
+// setBoolAttr adds (with an empty value) or removes the boolean attribute
+// of n.
+func setBoolAttr(d *Document, n *html.Node, name string, val bool) {
+	if !val {
+		removeAttr(d, n, name)
+	} else if !hasAttr(n, name) {
+		setAttr(d, n, name, "")
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
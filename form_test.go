@@ -0,0 +1,298 @@
+package goquery
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const formHTML = `<html><body>
+<form id="f" action="/login?x=1" method="post">
+	<input type="hidden" name="csrf" value="tok">
+	<input name="user" value="bob">
+	<input type="password" name="pass">
+	<input type="checkbox" name="remember">
+	<input type="checkbox" name="opts" value="a" checked>
+	<input type="checkbox" name="opts" value="b">
+	<input type="radio" name="color" value="red" checked>
+	<input type="radio" name="color" value="blue">
+	<input name="nope" value="x" disabled>
+	<input value="noname">
+	<select name="lang">
+		<option>en</option>
+		<option value="fr" selected>French</option>
+	</select>
+	<select name="tags" multiple>
+		<option value="t1" selected>T1</option>
+		<optgroup label="g" disabled><option value="t2" selected>T2</option></optgroup>
+		<option value="t3">T3</option>
+	</select>
+	<select name="first"><option disabled>d</option><option> two  words </option></select>
+	<textarea name="msg">line1
+line2</textarea>
+	<fieldset disabled>
+		<legend><input name="inlegend" value="1"></legend>
+		<input name="infieldset" value="1">
+	</fieldset>
+	<datalist><input name="indatalist" value="1"></datalist>
+	<input type="hidden" name="_charset_">
+	<button name="go" value="1">Go</button>
+	<input type="submit" name="alt" value="Alt" formaction="/other" formmethod="get">
+	<input type="reset" name="reset">
+</form>
+<input name="outside" value="1" form="f">
+<input name="unowned" value="1">
+</body></html>`
+
+func TestVal(t *testing.T) {
+	doc := loadString(t, formHTML)
+
+	cases := map[string]string{
+		"[name=user]":     "bob",
+		"[name=pass]":     "",
+		"[name=remember]": "on",
+		"[name=opts]":     "a",
+		"[name=lang]":     "fr",
+		"[name=tags]":     "t1",
+		"[name=first]":    "two words",
+		"[name=msg]":      "line1\nline2",
+		"[name=go]":       "1",
+		"option":          "en",
+		"legend":          "",
+		"#missing":        "",
+	}
+	for sel, want := range cases {
+		if got := doc.Find(sel).Val(); got != want {
+			t.Errorf("%s: want %q, got %q", sel, want, got)
+		}
+	}
+}
+
+func TestSetVal(t *testing.T) {
+	doc := loadString(t, formHTML)
+
+	doc.Find("[name=user]").SetVal("alice")
+	doc.Find("[name=msg]").SetVal("hello")
+	doc.Find("[name=opts]").SetVal("b")
+	doc.Find("[name=remember]").SetVal("on")
+	doc.Find("[name=color][value=blue]").SetVal("blue")
+	doc.Find("[name=lang]").SetVal("en", "fr")
+	doc.Find("[name=tags]").SetVal("t1", "t3")
+
+	if got := doc.Find("[name=user]").AttrOr("value", ""); got != "alice" {
+		t.Errorf("user: want alice, got %q", got)
+	}
+	if got := doc.Find("[name=msg]").Val(); got != "hello" {
+		t.Errorf("msg: want hello, got %q", got)
+	}
+	assertLength(t, doc.Find("[name=opts][checked]").Nodes, 1)
+	if got := doc.Find("[name=opts][checked]").Val(); got != "b" {
+		t.Errorf("opts: want b, got %q", got)
+	}
+	assertLength(t, doc.Find("[name=remember][checked]").Nodes, 1)
+	assertLength(t, doc.Find("[name=color][checked]").Nodes, 1)
+	if got := doc.Find("[name=color][checked]").Val(); got != "blue" {
+		t.Errorf("color: want blue, got %q", got)
+	}
+	// only the first matching option of a single select is selected
+	if got := doc.Find("[name=lang] option[selected]").Text(); got != "en" {
+		t.Errorf("lang: want en selected, got %q", got)
+	}
+	assertLength(t, doc.Find("[name=tags] option[selected]").Nodes, 2)
+
+	doc.Find("[name=opts]").SetVal()
+	assertLength(t, doc.Find("[name=opts][checked]").Nodes, 0)
+}
+
+func TestSetValRadioFormAttr(t *testing.T) {
+	doc := loadString(t, `<html><body>
+<form id="f"><input type="radio" name="c" value="a" checked></form>
+<input type="radio" name="c" value="b" form="f" checked>
+<input type="radio" name="c" value="c">
+</body></html>`)
+
+	doc.Find("[value=a]").SetVal("a")
+	assertLength(t, doc.Find("[value=b][checked]").Nodes, 0)
+	// not in the group of the form
+	doc.Find("[value=c]").SetVal("c")
+	assertLength(t, doc.Find("[value=a][checked]").Nodes, 1)
+}
+
+func TestFormValues(t *testing.T) {
+	doc := loadString(t, formHTML)
+	f := NewForm(doc.Find("input").First())
+	if f == nil {
+		t.Fatal("want a form, got nil")
+	}
+
+	want := url.Values{
+		"csrf":      {"tok"},
+		"user":      {"bob"},
+		"pass":      {""},
+		"opts":      {"a"},
+		"color":     {"red"},
+		"lang":      {"fr"},
+		"tags":      {"t1"},
+		"first":     {"two words"},
+		"msg":       {"line1\r\nline2"},
+		"inlegend":  {"1"},
+		"_charset_": {"UTF-8"},
+		"outside":   {"1"},
+	}
+	if got := f.Values(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	want.Set("go", "1")
+	if got := f.Values(doc.Find("[name=go]")); !reflect.DeepEqual(got, want) {
+		t.Errorf("with submitter: want %v, got %v", want, got)
+	}
+
+	want.Del("go")
+	if got := f.Values(doc.Find("[name=reset]")); !reflect.DeepEqual(got, want) {
+		t.Errorf("with a reset button: want %v, got %v", want, got)
+	}
+
+	f.Set("user", "alice").Set("color", "blue")
+	got := f.Values(nil)
+	if got.Get("user") != "alice" || got.Get("color") != "blue" {
+		t.Errorf("after Set: got %v", got)
+	}
+}
+
+func TestFormValuesDirname(t *testing.T) {
+	doc := loadString(t, `<form>
+<input name="q" dirname="q.dir" dir="rtl">
+<textarea name="t" dirname="t.dir">x</textarea>
+<select name="s" dirname="s.dir"><option>o</option></select>
+<button name="b" dirname="b.dir" value="1">go</button>
+</form>`)
+	f := NewForm(doc.Find("form"))
+	want := url.Values{
+		"q":     {""},
+		"q.dir": {"rtl"},
+		"t":     {"x"},
+		"t.dir": {"ltr"},
+		"s":     {"o"},
+		"b":     {"1"},
+	}
+	if got := f.Values(doc.Find("button")); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestNewForm(t *testing.T) {
+	doc := loadString(t, formHTML)
+
+	cases := []*Selection{
+		doc.Selection,
+		doc.Find("form"),
+		doc.Find("legend"),
+		doc.Find("[name=outside]"),
+	}
+	for i, sel := range cases {
+		f := NewForm(sel)
+		if f == nil || f.AttrOr("id", "") != "f" {
+			t.Errorf("%d: want form #f, got %v", i, f)
+		}
+	}
+	if f := NewForm(doc.Find("[name=unowned]")); f != nil {
+		t.Errorf("unowned: want nil, got %v", f)
+	}
+	if f := NewForm(doc.Find("#missing")); f != nil {
+		t.Errorf("empty: want nil, got %v", f)
+	}
+}
+
+func TestFormRequest(t *testing.T) {
+	doc := loadStringURL(t, `<form action="search" method="bogus">
+		<input name="q" value="a b&c"><input name="q" value="é">
+		<button name="go" formmethod="post" formenctype="text/plain">Go</button>
+	</form>`, "http://example.com/dir/page?z=1")
+	f := NewForm(doc.Selection)
+
+	req, err := f.Request(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "GET" || req.URL.String() != "http://example.com/dir/search?q=a+b%26c&q=%C3%A9" {
+		t.Errorf("GET: got %s %s", req.Method, req.URL)
+	}
+
+	req, err = f.Request(doc.Find("button"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	if req.Method != "POST" || req.Header.Get("Content-Type") != "text/plain" ||
+		string(body) != "q=a b&c\r\nq=é\r\ngo=\r\n" {
+		t.Errorf("text/plain: got %s %q %q", req.Method, req.Header.Get("Content-Type"), body)
+	}
+}
+
+func TestFormRequestEmptyAction(t *testing.T) {
+	doc := loadStringURL(t, `<form method="post"><input name="a" value="1"></form>`,
+		"http://example.com/page?z=1")
+
+	req, err := NewForm(doc.Selection).Request(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	if req.URL.String() != "http://example.com/page?z=1" ||
+		req.Header.Get("Content-Type") != "application/x-www-form-urlencoded" || string(body) != "a=1" {
+		t.Errorf("got %s %q %q", req.URL, req.Header.Get("Content-Type"), body)
+	}
+}
+
+func TestFormRequestMultipart(t *testing.T) {
+	doc := loadStringURL(t, `<form action="/up" method="post" enctype="multipart/form-data">
+		<input name="title" value="hi">
+		<input type="file" name="doc">
+		<input type="file" name="empty">
+	</form>`, "http://example.com/")
+	f := NewForm(doc.Selection)
+	f.Files = map[string][]FormFile{
+		"doc": {{Filename: "a.txt", ContentType: "text/plain", Content: strings.NewReader("content")}},
+	}
+
+	req, err := f.Request(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mt != "multipart/form-data" {
+		t.Fatalf("want multipart/form-data, got %q (%v)", mt, err)
+	}
+
+	type part struct{ name, filename, ctype, body string }
+	var got []part
+	mr := multipart.NewReader(req.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		b, _ := ioutil.ReadAll(p)
+		got = append(got, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(b)})
+	}
+	want := []part{
+		{"title", "", "", "hi"},
+		{"doc", "a.txt", "text/plain", "content"},
+		{"empty", "", "application/octet-stream", ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestFormRequestDialog(t *testing.T) {
+	doc := loadString(t, `<form method="dialog"></form>`)
+	if _, err := NewForm(doc.Selection).Request(nil); err == nil {
+		t.Error("want an error for the dialog method")
+	}
+}
@@ -3,6 +3,7 @@ module github.com/PuerkitoBio/goquery
 require (
 	github.com/andybalholm/cascadia v1.1.0
 	golang.org/x/net v0.0.0-20200202094626-16171245cfb2
+	golang.org/x/text v0.3.0
 )
 
 go 1.13
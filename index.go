@@ -0,0 +1,271 @@
+package goquery
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// nodeIndex maps the ids, classes and tag names of the elements of a tree
+// to those elements, in document order.
+type nodeIndex struct {
+	ids     map[string][]*html.Node
+	classes map[string][]*html.Node
+	tags    map[string][]*html.Node
+}
+
+// docIndex is the index of the elements of a Document, see BuildIndex.
+type docIndex struct {
+	mu      sync.Mutex
+	indexed bool
+	// nil when it has been invalidated by a change
+	idx *nodeIndex
+}
+
+// BuildIndex builds an index of the elements of the document by id, class
+// and tag name, which is then used by Find and Add (on any Selection of the
+// document) for the selectors made of a single id, class or type selector,
+// such as "#main", ".item" or "a", instead of walking the document tree.
+// The index is only referenced by the Document, so it is released with it.
+//
+// The changes made to the document through its Selections invalidate the
+// index, which is rebuilt on its next use, so it is best suited to
+// documents that are queried much more often than they are modified. The
+// index is also rebuilt when it holds nodes that were moved out of the
+// document, but the other changes made directly to the html.Node values,
+// or through another Document created on the same nodes, are not
+// detected, and DropIndex (then BuildIndex again, if needed) must be
+// called after them. Like the changes to the document, BuildIndex and
+// DropIndex must not be called concurrently with other methods.
+func (d *Document) BuildIndex() {
+	if d.index == nil {
+		d.index = &docIndex{}
+	}
+	d.index.mu.Lock()
+	defer d.index.mu.Unlock()
+	d.index.indexed = true
+	d.index.idx = buildNodeIndex(d.rootNode)
+}
+
+// DropIndex drops the index built by BuildIndex, if any.
+func (d *Document) DropIndex() {
+	if d.index == nil {
+		return
+	}
+	d.index.mu.Lock()
+	defer d.index.mu.Unlock()
+	d.index.indexed = false
+	d.index.idx = nil
+}
+
+// invalidate drops the index if the change m may have made it
+// inconsistent.
+func (x *docIndex) invalidate(m mutation) {
+	if m.kind == characterDataMutation ||
+		(m.kind == attributesMutation && m.attr != "id" && m.attr != "class") {
+		return
+	}
+	x.mu.Lock()
+	x.idx = nil
+	x.mu.Unlock()
+}
+
+// nodeIndex returns the index of the document, building it if it has been
+// invalidated or if it is stale, or nil if there is none.
+func (d *Document) nodeIndex(stale *nodeIndex) *nodeIndex {
+	x := d.index
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.indexed {
+		return nil
+	}
+	if x.idx == nil || x.idx == stale {
+		x.idx = buildNodeIndex(d.rootNode)
+	}
+	return x.idx
+}
+
+// lookup returns the elements with the id key if kind is '#', the class key
+// if kind is '.', or the tag name key otherwise.
+func (idx *nodeIndex) lookup(kind byte, key string) []*html.Node {
+	switch kind {
+	case '#':
+		return idx.ids[key]
+	case '.':
+		return idx.classes[key]
+	}
+	return idx.tags[key]
+}
+
+func buildNodeIndex(root *html.Node) *nodeIndex {
+	idx := &nodeIndex{
+		ids:     make(map[string][]*html.Node),
+		classes: make(map[string][]*html.Node),
+		tags:    make(map[string][]*html.Node),
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			idx.tags[n.Data] = append(idx.tags[n.Data], n)
+			for _, a := range n.Attr {
+				switch a.Key {
+				case "id":
+					idx.ids[a.Val] = append(idx.ids[a.Val], n)
+				case "class":
+					for _, c := range strings.FieldsFunc(a.Val, isClassSeparator) {
+						// a class may be repeated in the attribute
+						if l := idx.classes[c]; len(l) == 0 || l[len(l)-1] != n {
+							idx.classes[c] = append(l, n)
+						}
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return idx
+}
+
+// isClassSeparator returns true if r separates the classes of a class
+// attribute, as when matched by a class selector.
+func isClassSeparator(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\f'
+}
+
+// findWithSelector is like findWithMatcher with the compiled selector, but
+// uses the index of the document d, which may be nil, if there is one and
+// the selector is simple enough.
+func findWithSelector(d *Document, nodes []*html.Node, selector string) []*html.Node {
+	if d != nil && d.index != nil && len(nodes) > 0 {
+		if result, ok := d.findIndexed(nodes, selector); ok {
+			return result
+		}
+	}
+	return findWithMatcher(nodes, compileMatcher(selector))
+}
+
+// findIndexed returns the same nodes as findWithMatcher for the selector,
+// using the index, or false if there is no index, if the selector is not a
+// single id, class or type selector, or if not all nodes are in the
+// document.
+func (d *Document) findIndexed(nodes []*html.Node, selector string) ([]*html.Node, bool) {
+	if len(selector) == 0 {
+		return nil, false
+	}
+	var kind byte
+	var key string
+	switch c := selector[0]; {
+	case c == '#' && isSimpleName(selector[1:]):
+		kind, key = c, selector[1:]
+	case c == '.' && isSimpleIdent(selector[1:]):
+		kind, key = c, selector[1:]
+	case isSimpleIdent(selector):
+		key = toLowerASCII(selector)
+	default:
+		return nil, false
+	}
+
+	// the position of each node in the selection, to return the
+	// descendants of the first one first, like findWithMatcher
+	pos := make(map[*html.Node]int, len(nodes))
+	for i, n := range nodes {
+		if !d.contains(n) {
+			return nil, false
+		}
+		if _, ok := pos[n]; !ok {
+			pos[n] = i
+		}
+	}
+
+	idx := d.nodeIndex(nil)
+	if idx == nil {
+		return nil, false
+	}
+	candidates := idx.lookup(kind, key)
+	// the nodes moved out of the document through another Document are not
+	// detected, the index is rebuilt if there are such nodes
+	for _, c := range candidates {
+		if !d.contains(c) {
+			if idx = d.nodeIndex(idx); idx == nil {
+				return nil, false
+			}
+			candidates = idx.lookup(kind, key)
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, true
+	}
+
+	if len(pos) == 1 && nodes[0] == d.rootNode {
+		result := make([]*html.Node, 0, len(candidates))
+		for _, c := range candidates {
+			if c != d.rootNode {
+				result = append(result, c)
+			}
+		}
+		return result, true
+	}
+
+	groups := make([][]*html.Node, len(nodes))
+	for _, c := range candidates {
+		first := -1
+		for p := c.Parent; p != nil; p = p.Parent {
+			if i, ok := pos[p]; ok && (first < 0 || i < first) {
+				first = i
+			}
+		}
+		if first >= 0 {
+			groups[first] = append(groups[first], c)
+		}
+	}
+	var result []*html.Node
+	for _, g := range groups {
+		result = append(result, g...)
+	}
+	return result, true
+}
+
+// isSimpleIdent returns true if s is a CSS identifier made only of ASCII
+// letters, digits, hyphens and underscores.
+func isSimpleIdent(s string) bool {
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	}
+	if len(s) == 0 || !isNameStart(s[0]) {
+		return false
+	}
+	return isSimpleName(s)
+}
+
+// isSimpleName is like isSimpleIdent, but for the names that may start with
+// any name character, such as ids.
+func isSimpleName(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isNameStart(c) && c != '-' && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func toLowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= 'A' && c <= 'Z' {
+			return strings.ToLower(s)
+		}
+	}
+	return s
+}
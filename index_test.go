@@ -0,0 +1,88 @@
+package goquery
+
+import (
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func assertIndexedNodes(t *testing.T, selector string, got, want []*html.Node) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: want %d nodes, got %d", selector, len(want), len(got))
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: node %d differs: want %+v, got %+v", selector, i, want[i], got[i])
+			return
+		}
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	doc := CloneDocument(DocW())
+	selectors := []string{"a", "LI", "span", "#toc", "#footer", ".toclevel-1", ".mw-headline", "div", "#nope", ".nope", "nope"}
+	want := make([][]*html.Node, len(selectors))
+	for i, sel := range selectors {
+		want[i] = doc.Find(sel).Nodes
+	}
+	wantAdd := doc.Find("h1").Add(".toclevel-2").Nodes
+	wantNested := doc.Find("ul").Find("a").Nodes
+
+	doc.BuildIndex()
+	defer doc.DropIndex()
+	for i, sel := range selectors {
+		assertIndexedNodes(t, sel, doc.Find(sel).Nodes, want[i])
+	}
+	assertIndexedNodes(t, "add", doc.Find("h1").Add(".toclevel-2").Nodes, wantAdd)
+	assertIndexedNodes(t, "nested", doc.Find("ul").Find("a").Nodes, wantNested)
+}
+
+func TestBuildIndexMutations(t *testing.T) {
+	doc := Doc2Clone()
+	doc.BuildIndex()
+
+	assertLength(t, doc.Find("#main").Nodes, 1)
+	doc.Find("#main").SetAttr("id", "x")
+	assertLength(t, doc.Find("#main").Nodes, 0)
+	assertLength(t, doc.Find("#x").Nodes, 1)
+
+	doc.Find("#x").AddClass("a b")
+	assertLength(t, doc.Find(".b").Nodes, 1)
+	doc.Find("#x").RemoveClass("b")
+	assertLength(t, doc.Find(".b").Nodes, 0)
+
+	doc.Find("#x").AppendHtml(`<section class="b"><section></section></section>`)
+	assertLength(t, doc.Find("section").Nodes, 2)
+	assertLength(t, doc.Find(".b").Nodes, 1)
+	doc.Find("section").Remove()
+	assertLength(t, doc.Find("section").Nodes, 0)
+
+	tx := doc.Begin()
+	doc.Find("#x").SetAttr("id", "y")
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, doc.Find("#x").Nodes, 1)
+	assertLength(t, doc.Find("#y").Nodes, 0)
+
+	doc.DropIndex()
+	if doc.index.idx != nil {
+		t.Error("expected the index to be dropped")
+	}
+	assertLength(t, doc.Find("#x").Nodes, 1)
+}
+
+func TestBuildIndexMovedByOtherDocument(t *testing.T) {
+	d1 := loadString(t, `<html><body><div id="x"></div></body></html>`)
+	d2 := loadString(t, `<html><body><p>a</p><p>b</p></body></html>`)
+	d2.BuildIndex()
+	assertLength(t, d2.Find("p").Nodes, 2)
+
+	d1.Find("#x").AppendSelection(d2.Find("p"))
+	assertLength(t, d2.FindMatcher(compileMatcher("p")).Nodes, 0)
+	assertLength(t, d2.Find("p").Nodes, 0)
+	assertLength(t, d2.Find("body").Find("p").Nodes, 0)
+	assertLength(t, d1.Find("p").Nodes, 2)
+}
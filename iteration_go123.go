@@ -0,0 +1,158 @@
+//go:build go1.23
+
+package goquery
+
+import (
+	"iter"
+
+	"golang.org/x/net/html"
+)
+
+// All returns an iterator over the Selection object's matched elements, with
+// the index of the element in that selection starting at 0, and a *Selection
+// that contains only that element. It can be used with a range-over-func
+// loop, and allows breaking out of the loop like EachWithBreak.
+func (s *Selection) All() iter.Seq2[int, *Selection] {
+	return func(yield func(int, *Selection) bool) {
+		for i, n := range s.Nodes {
+			if !yield(i, newSingleSelection(n, s.document)) {
+				return
+			}
+		}
+	}
+}
+
+// Backward is like All, except that it iterates over the matched elements
+// from the last to the first. The indices are still those of the elements
+// in the selection, so they go from Length()-1 down to 0.
+func (s *Selection) Backward() iter.Seq2[int, *Selection] {
+	return func(yield func(int, *Selection) bool) {
+		for i := len(s.Nodes) - 1; i >= 0; i-- {
+			if !yield(i, newSingleSelection(s.Nodes[i], s.document)) {
+				return
+			}
+		}
+	}
+}
+
+// NodesIter returns an iterator over the Selection object's matched nodes,
+// with the index of the node in that selection starting at 0. Unlike All, no
+// *Selection is created for each node.
+func (s *Selection) NodesIter() iter.Seq2[int, *html.Node] {
+	return func(yield func(int, *html.Node) bool) {
+		for i, n := range s.Nodes {
+			if !yield(i, n) {
+				return
+			}
+		}
+	}
+}
+
+// FindIter returns an iterator over the descendants of each element in the
+// current set of matched elements, filtered by a selector. It yields the same
+// elements as Find, in the same order, but the document tree is walked lazily
+// as the iteration goes, so breaking out of the loop stops the search.
+func (s *Selection) FindIter(selector string) iter.Seq2[int, *Selection] {
+	return s.FindIterMatcher(compileMatcher(selector))
+}
+
+// FindIterMatcher is like FindIter, filtered by the matcher.
+func (s *Selection) FindIterMatcher(m Matcher) iter.Seq2[int, *Selection] {
+	return s.iterNodes(func(n *html.Node, yield func(*html.Node) bool) bool {
+		// Go down one level, because jQuery's Find selects only within descendants
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && !walkMatches(c, m, yield) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// ParentsIter returns an iterator over the ancestors of each element in the
+// current Selection. It yields the same elements as Parents, in the same
+// order, walking up the tree lazily.
+func (s *Selection) ParentsIter() iter.Seq2[int, *Selection] {
+	return s.iterNodes(func(n *html.Node, yield func(*html.Node) bool) bool {
+		for p := n.Parent; p != nil; p = p.Parent {
+			if p.Type == html.ElementNode && !yield(p) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// NextAllIter returns an iterator over all the following siblings of each
+// element in the Selection. It yields the same elements as NextAll, in the
+// same order, walking the siblings lazily.
+func (s *Selection) NextAllIter() iter.Seq2[int, *Selection] {
+	return s.iterNodes(func(n *html.Node, yield func(*html.Node) bool) bool {
+		for c := n.NextSibling; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && !yield(c) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// PrevAllIter returns an iterator over all the preceding siblings of each
+// element in the Selection. It yields the same elements as PrevAll, in the
+// same order, walking the siblings lazily.
+func (s *Selection) PrevAllIter() iter.Seq2[int, *Selection] {
+	return s.iterNodes(func(n *html.Node, yield func(*html.Node) bool) bool {
+		for c := n.PrevSibling; c != nil; c = c.PrevSibling {
+			if c.Type == html.ElementNode && !yield(c) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// iterNodes is the lazy counterpart of mapNodes: it calls walk for each node
+// in the selection, and yields the nodes it produces as single-node
+// selections, skipping duplicates. The walk function must stop and return
+// false as soon as its yield function returns false.
+func (s *Selection) iterNodes(walk func(*html.Node, func(*html.Node) bool) bool) iter.Seq2[int, *Selection] {
+	return func(yield func(int, *Selection) bool) {
+		var i int
+		var set map[*html.Node]bool
+		if len(s.Nodes) > 1 {
+			// duplicates are only possible when walking from multiple nodes
+			set = make(map[*html.Node]bool)
+		}
+
+		f := func(n *html.Node) bool {
+			if set != nil {
+				if set[n] {
+					return true
+				}
+				set[n] = true
+			}
+			ok := yield(i, newSingleSelection(n, s.document))
+			i++
+			return ok
+		}
+		for _, n := range s.Nodes {
+			if !walk(n, f) {
+				return
+			}
+		}
+	}
+}
+
+// walkMatches calls yield for n and each of its descendants that match m, in
+// depth-first order, like m.MatchAll(n). It returns false if yield did.
+func walkMatches(n *html.Node, m Matcher, yield func(*html.Node) bool) bool {
+	if m.Match(n) && !yield(n) {
+		return false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && !walkMatches(c, m, yield) {
+			return false
+		}
+	}
+	return true
+}
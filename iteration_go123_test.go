@@ -0,0 +1,129 @@
+//go:build go1.23
+
+package goquery
+
+import (
+	"iter"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// collect returns the nodes yielded by seq, checking that the indices are
+// sequential.
+func collect(t *testing.T, seq iter.Seq2[int, *Selection]) []*html.Node {
+	var nodes []*html.Node
+	for i, s := range seq {
+		if i != len(nodes) {
+			t.Errorf("expected index %d, got %d", len(nodes), i)
+		}
+		if s.Length() != 1 {
+			t.Errorf("%d: expected length of 1, got %d", i, s.Length())
+		}
+		nodes = append(nodes, s.Get(0))
+	}
+	return nodes
+}
+
+func assertSameNodes(t *testing.T, got, want []*html.Node) {
+	if len(got) != len(want) {
+		t.Fatalf("expected %d nodes, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%d: expected node %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	sel := Doc().Find(".hero-unit .row-fluid")
+	assertSameNodes(t, collect(t, sel.All()), sel.Nodes)
+}
+
+func TestAllBreak(t *testing.T) {
+	var cnt int
+	for range Doc().Find(".hero-unit .row-fluid").All() {
+		cnt++
+		break
+	}
+	if cnt != 1 {
+		t.Errorf("Expected loop to run 1 time, got %d times.", cnt)
+	}
+}
+
+func TestAllEmptySelection(t *testing.T) {
+	for range Doc().Find("zzzz").All() {
+		t.Error("Expected no iteration on empty Selection.")
+	}
+}
+
+func TestBackward(t *testing.T) {
+	sel := Doc().Find(".pvk-content")
+	want := len(sel.Nodes) - 1
+	for i, s := range sel.Backward() {
+		if i != want {
+			t.Errorf("expected index %d, got %d", want, i)
+		}
+		if s.Get(0) != sel.Nodes[i] {
+			t.Errorf("%d: unexpected node %+v", i, s.Get(0))
+		}
+		want--
+	}
+	if want != -1 {
+		t.Errorf("expected all nodes to be visited, %d left", want+1)
+	}
+}
+
+func TestNodesIter(t *testing.T) {
+	sel := Doc().Find(".pvk-content")
+	var nodes []*html.Node
+	for i, n := range sel.NodesIter() {
+		if i != len(nodes) {
+			t.Errorf("expected index %d, got %d", len(nodes), i)
+		}
+		nodes = append(nodes, n)
+	}
+	assertSameNodes(t, nodes, sel.Nodes)
+}
+
+func TestFindIter(t *testing.T) {
+	sel := Doc().Find(".pvk-content")
+	assertSameNodes(t, collect(t, sel.FindIter("div")), sel.Find("div").Nodes)
+}
+
+func TestFindIterOverlapping(t *testing.T) {
+	// selection contains nested elements, results must not be duplicated
+	sel := Doc().Find("div")
+	assertSameNodes(t, collect(t, sel.FindIter("p")), sel.Find("p").Nodes)
+}
+
+func TestFindIterInvalid(t *testing.T) {
+	assertLength(t, collect(t, Doc().FindIter("~")), 0)
+}
+
+func TestFindIterBreak(t *testing.T) {
+	var n *html.Node
+	for _, s := range DocW().FindIter("a") {
+		n = s.Get(0)
+		break
+	}
+	if n != DocW().Find("a").Get(0) {
+		t.Errorf("expected the first a, got %+v", n)
+	}
+}
+
+func TestParentsIter(t *testing.T) {
+	sel := Doc2().Find(".five, #nf1")
+	assertSameNodes(t, collect(t, sel.ParentsIter()), sel.Parents().Nodes)
+}
+
+func TestNextAllIter(t *testing.T) {
+	sel := Doc2().Find("#n2, #nf4")
+	assertSameNodes(t, collect(t, sel.NextAllIter()), sel.NextAll().Nodes)
+}
+
+func TestPrevAllIter(t *testing.T) {
+	sel := Doc2().Find("#n4, #nf2")
+	assertSameNodes(t, collect(t, sel.PrevAllIter()), sel.PrevAll().Nodes)
+}
@@ -0,0 +1,147 @@
+package goquery
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// DefaultContentTypes is the list of media types accepted by a Loader when
+// its ContentTypes field is empty.
+var DefaultContentTypes = []string{"text/html", "application/xhtml+xml"}
+
+// StatusError is the error returned by Loader.Load when the status code of
+// the response is not accepted.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("goquery: unexpected status for %s: %s", e.URL, e.Status)
+}
+
+// ContentTypeError is the error returned by Loader.Load when the media type
+// of the response is not accepted.
+type ContentTypeError struct {
+	URL         string
+	ContentType string
+}
+
+// Error implements the error interface.
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("goquery: unexpected content type for %s: %q", e.URL, e.ContentType)
+}
+
+// Loader loads documents over HTTP. Its zero value is ready to use, all
+// fields are optional. A Loader is safe for concurrent use as long as its
+// fields are not modified.
+type Loader struct {
+	// Client is the HTTP client used to make the requests. If nil,
+	// http.DefaultClient is used. Timeouts can be set on the client or via
+	// the context passed to Load.
+	Client *http.Client
+
+	// MaxBytes is the maximum number of bytes read from the response body.
+	// If the body is larger, ErrMaxBytesExceeded is returned. A value of 0
+	// or less means no limit.
+	MaxBytes int64
+
+	// UserAgent is the value of the User-Agent header sent with the
+	// requests. If empty, the client's default is used.
+	UserAgent string
+
+	// AcceptStatus reports whether the status code of the response is
+	// accepted. If nil, only 2xx status codes are. If the status code is not
+	// accepted, a *StatusError is returned.
+	AcceptStatus func(code int) bool
+
+	// ContentTypes is the list of accepted media types of the response. If
+	// empty, DefaultContentTypes is used. A response without Content-Type
+	// header is always accepted. If the media type is not accepted, a
+	// *ContentTypeError is returned.
+	ContentTypes []string
+
+	// Options are additional options used to create the documents. The
+	// loader already sets the URL and charset detection, and the maximum
+	// number of bytes if MaxBytes is set.
+	Options []Option
+}
+
+// Load makes a GET request to the url and returns the Document parsed from
+// the response, after validating the response's status code and content
+// type. The character encoding of the document is detected and converted
+// to UTF-8 (see NewDocumentFromReaderWithCharset). The Document's Url is
+// set to the final URL of the request, after any redirect. The request is
+// bound to the provided context.
+func (l *Loader) Load(ctx context.Context, url string) (*Document, error) {
+	req, e := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if e != nil {
+		return nil, e
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	if l.UserAgent != "" {
+		req.Header.Set("User-Agent", l.UserAgent)
+	}
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, e := client.Do(req)
+	if e != nil {
+		return nil, e
+	}
+	defer res.Body.Close()
+
+	acceptStatus := l.AcceptStatus
+	if acceptStatus == nil {
+		acceptStatus = isSuccessStatus
+	}
+	if !acceptStatus(res.StatusCode) {
+		return nil, &StatusError{URL: url, StatusCode: res.StatusCode, Status: res.Status}
+	}
+
+	ct := res.Header.Get("Content-Type")
+	if !l.acceptContentType(ct) {
+		return nil, &ContentTypeError{URL: url, ContentType: ct}
+	}
+
+	opts := make([]Option, 0, len(l.Options)+3)
+	opts = append(opts, l.Options...)
+	opts = append(opts, WithURL(res.Request.URL), WithCharset(ct))
+	if l.MaxBytes > 0 {
+		opts = append(opts, WithMaxBytes(l.MaxBytes))
+	}
+	return NewDocumentFromReaderWithOptions(res.Body, opts...)
+}
+
+// acceptContentType reports whether the media type of the Content-Type
+// header value ct is accepted by the loader.
+func (l *Loader) acceptContentType(ct string) bool {
+	if ct == "" {
+		return true
+	}
+	mt, _, e := mime.ParseMediaType(ct)
+	if e != nil {
+		return false
+	}
+
+	types := l.ContentTypes
+	if len(types) == 0 {
+		types = DefaultContentTypes
+	}
+	for _, t := range types {
+		if mt == t {
+			return true
+		}
+	}
+	return false
+}
+
+func isSuccessStatus(code int) bool {
+	return code >= 200 && code < 300
+}
@@ -0,0 +1,170 @@
+package goquery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLoaderTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, `<html><body><h1>`+r.UserAgent()+`</h1></body></html>`)
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		io.WriteString(w, "<html><body><h1>Caf\xe9</h1></body></html>")
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `<html><body><h1>Not found</h1></body></html>`)
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{}`)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, `<html><body>`+strings.Repeat("<p>x</p>", 1000)+`</body></html>`)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestLoaderLoad(t *testing.T) {
+	srv := newLoaderTestServer()
+	defer srv.Close()
+
+	l := &Loader{UserAgent: "goquery-test"}
+	d, err := l.Load(context.Background(), srv.URL+"/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Find("h1").Text(); got != "goquery-test" {
+		t.Errorf("expected user agent to be sent, got %q", got)
+	}
+	if got := d.Url.String(); got != srv.URL+"/page" {
+		t.Errorf("expected Url %s, got %s", srv.URL+"/page", got)
+	}
+	if d.Encoding != "utf-8" {
+		t.Errorf("expected encoding utf-8, got %q", d.Encoding)
+	}
+}
+
+func TestLoaderLoadCharset(t *testing.T) {
+	srv := newLoaderTestServer()
+	defer srv.Close()
+
+	var l Loader
+	d, err := l.Load(context.Background(), srv.URL+"/latin1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Find("h1").Text(); got != "Café" {
+		t.Errorf("expected Café, got %q", got)
+	}
+}
+
+func TestLoaderLoadRedirect(t *testing.T) {
+	srv := newLoaderTestServer()
+	defer srv.Close()
+
+	var l Loader
+	d, err := l.Load(context.Background(), srv.URL+"/redirect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Url.String(); got != srv.URL+"/page" {
+		t.Errorf("expected final Url %s, got %s", srv.URL+"/page", got)
+	}
+}
+
+func TestLoaderLoadStatus(t *testing.T) {
+	srv := newLoaderTestServer()
+	defer srv.Close()
+
+	var l Loader
+	_, err := l.Load(context.Background(), srv.URL+"/missing")
+	se, ok := err.(*StatusError)
+	if !ok {
+		t.Fatalf("expected *StatusError, got %T: %v", err, err)
+	}
+	if se.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status code 404, got %d", se.StatusCode)
+	}
+
+	l.AcceptStatus = func(code int) bool { return code == http.StatusNotFound }
+	d, err := l.Load(context.Background(), srv.URL+"/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Find("h1").Text(); got != "Not found" {
+		t.Errorf("expected Not found, got %q", got)
+	}
+}
+
+func TestLoaderLoadContentType(t *testing.T) {
+	srv := newLoaderTestServer()
+	defer srv.Close()
+
+	var l Loader
+	_, err := l.Load(context.Background(), srv.URL+"/json")
+	cte, ok := err.(*ContentTypeError)
+	if !ok {
+		t.Fatalf("expected *ContentTypeError, got %T: %v", err, err)
+	}
+	if cte.ContentType != "application/json" {
+		t.Errorf("expected content type application/json, got %q", cte.ContentType)
+	}
+
+	l.ContentTypes = []string{"application/json"}
+	if _, err := l.Load(context.Background(), srv.URL+"/json"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestLoaderLoadMaxBytes(t *testing.T) {
+	srv := newLoaderTestServer()
+	defer srv.Close()
+
+	l := Loader{MaxBytes: 1000}
+	if _, err := l.Load(context.Background(), srv.URL+"/large"); err != ErrMaxBytesExceeded {
+		t.Errorf("expected ErrMaxBytesExceeded, got %v", err)
+	}
+	if _, err := l.Load(context.Background(), srv.URL+"/page"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// the option is not overridden when MaxBytes is not set
+	l = Loader{Options: []Option{WithMaxBytes(1000)}}
+	if _, err := l.Load(context.Background(), srv.URL+"/large"); err != ErrMaxBytesExceeded {
+		t.Errorf("expected ErrMaxBytesExceeded with the option, got %v", err)
+	}
+}
+
+func TestLoaderLoadContext(t *testing.T) {
+	srv := newLoaderTestServer()
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var l Loader
+	if _, err := l.Load(ctx, srv.URL+"/slow"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -1,6 +1,7 @@
 package goquery
 
 import (
+	"fmt"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -37,14 +38,26 @@ func (s *Selection) AfterSelection(sel *Selection) *Selection {
 }
 
 // AfterHtml parses the html and inserts it after the set of matched elements.
+// It panics if the html cannot be parsed in the context of the elements'
+// parents, see AfterHtmlE.
 //
 // This follows the same rules as Selection.Append.
 func (s *Selection) AfterHtml(htmlStr string) *Selection {
-	return s.eachNodeHtml(htmlStr, true, func(node *html.Node, nodes []*html.Node) {
+	return mustManipulate(s.AfterHtmlE(htmlStr))
+}
+
+// AfterHtmlE is like AfterHtml, except that it returns an error instead of
+// panicking if the html cannot be parsed. The html is parsed for all the
+// elements before the document is modified, so the document is left
+// unchanged on error. The options can limit the size of the html (see
+// WithMaxBytes, WithMaxNodes and WithMaxDepth) and configure the parser
+// (see WithScripting), the other options are ignored.
+func (s *Selection) AfterHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	return s, s.eachNodeHtml(htmlStr, true, opts, func(node *html.Node, nodes []*html.Node) {
 		nextSibling := node.NextSibling
 		for _, n := range nodes {
 			if node.Parent != nil {
-				node.Parent.InsertBefore(n, nextSibling)
+				insertBefore(s.document, node.Parent, n, nextSibling)
 			}
 		}
 	})
@@ -56,7 +69,7 @@ func (s *Selection) AfterHtml(htmlStr string) *Selection {
 func (s *Selection) AfterNodes(ns ...*html.Node) *Selection {
 	return s.manipulateNodes(ns, true, func(sn *html.Node, n *html.Node) {
 		if sn.Parent != nil {
-			sn.Parent.InsertBefore(n, sn.NextSibling)
+			insertBefore(s.document, sn.Parent, n, sn.NextSibling)
 		}
 	})
 }
@@ -92,10 +105,19 @@ func (s *Selection) AppendSelection(sel *Selection) *Selection {
 }
 
 // AppendHtml parses the html and appends it to the set of matched elements.
+// It panics if the html cannot be parsed in the context of the elements,
+// see AppendHtmlE.
 func (s *Selection) AppendHtml(htmlStr string) *Selection {
-	return s.eachNodeHtml(htmlStr, false, func(node *html.Node, nodes []*html.Node) {
+	return mustManipulate(s.AppendHtmlE(htmlStr))
+}
+
+// AppendHtmlE is like AppendHtml, except that it returns an error instead
+// of panicking if the html cannot be parsed, leaving the document
+// unchanged. See AfterHtmlE for the supported options.
+func (s *Selection) AppendHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	return s, s.eachNodeHtml(htmlStr, false, opts, func(node *html.Node, nodes []*html.Node) {
 		for _, n := range nodes {
-			node.AppendChild(n)
+			appendChild(s.document, node, n)
 		}
 	})
 }
@@ -105,7 +127,7 @@ func (s *Selection) AppendHtml(htmlStr string) *Selection {
 // This follows the same rules as Selection.Append.
 func (s *Selection) AppendNodes(ns ...*html.Node) *Selection {
 	return s.manipulateNodes(ns, false, func(sn *html.Node, n *html.Node) {
-		sn.AppendChild(n)
+		appendChild(s.document, sn, n)
 	})
 }
 
@@ -132,13 +154,22 @@ func (s *Selection) BeforeSelection(sel *Selection) *Selection {
 }
 
 // BeforeHtml parses the html and inserts it before the set of matched elements.
+// It panics if the html cannot be parsed in the context of the elements'
+// parents, see BeforeHtmlE.
 //
 // This follows the same rules as Selection.Append.
 func (s *Selection) BeforeHtml(htmlStr string) *Selection {
-	return s.eachNodeHtml(htmlStr, true, func(node *html.Node, nodes []*html.Node) {
+	return mustManipulate(s.BeforeHtmlE(htmlStr))
+}
+
+// BeforeHtmlE is like BeforeHtml, except that it returns an error instead
+// of panicking if the html cannot be parsed, leaving the document
+// unchanged. See AfterHtmlE for the supported options.
+func (s *Selection) BeforeHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	return s, s.eachNodeHtml(htmlStr, true, opts, func(node *html.Node, nodes []*html.Node) {
 		for _, n := range nodes {
 			if node.Parent != nil {
-				node.Parent.InsertBefore(n, node)
+				insertBefore(s.document, node.Parent, n, node)
 			}
 		}
 	})
@@ -150,7 +181,7 @@ func (s *Selection) BeforeHtml(htmlStr string) *Selection {
 func (s *Selection) BeforeNodes(ns ...*html.Node) *Selection {
 	return s.manipulateNodes(ns, false, func(sn *html.Node, n *html.Node) {
 		if sn.Parent != nil {
-			sn.Parent.InsertBefore(n, sn)
+			insertBefore(s.document, sn.Parent, n, sn)
 		}
 	})
 }
@@ -170,7 +201,7 @@ func (s *Selection) Empty() *Selection {
 
 	for _, n := range s.Nodes {
 		for c := n.FirstChild; c != nil; c = n.FirstChild {
-			n.RemoveChild(c)
+			removeChild(s.document, n, c)
 			nodes = append(nodes, c)
 		}
 	}
@@ -201,11 +232,20 @@ func (s *Selection) PrependSelection(sel *Selection) *Selection {
 }
 
 // PrependHtml parses the html and prepends it to the set of matched elements.
+// It panics if the html cannot be parsed in the context of the elements,
+// see PrependHtmlE.
 func (s *Selection) PrependHtml(htmlStr string) *Selection {
-	return s.eachNodeHtml(htmlStr, false, func(node *html.Node, nodes []*html.Node) {
+	return mustManipulate(s.PrependHtmlE(htmlStr))
+}
+
+// PrependHtmlE is like PrependHtml, except that it returns an error instead
+// of panicking if the html cannot be parsed, leaving the document
+// unchanged. See AfterHtmlE for the supported options.
+func (s *Selection) PrependHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	return s, s.eachNodeHtml(htmlStr, false, opts, func(node *html.Node, nodes []*html.Node) {
 		firstChild := node.FirstChild
 		for _, n := range nodes {
-			node.InsertBefore(n, firstChild)
+			insertBefore(s.document, node, n, firstChild)
 		}
 	})
 }
@@ -217,8 +257,8 @@ func (s *Selection) PrependHtml(htmlStr string) *Selection {
 func (s *Selection) PrependNodes(ns ...*html.Node) *Selection {
 	return s.manipulateNodes(ns, true, func(sn *html.Node, n *html.Node) {
 		// sn.FirstChild may be nil, in which case this functions like
-		// sn.AppendChild()
-		sn.InsertBefore(n, sn.FirstChild)
+		// appendChild()
+		insertBefore(s.document, sn, n, sn.FirstChild)
 	})
 }
 
@@ -227,7 +267,7 @@ func (s *Selection) PrependNodes(ns ...*html.Node) *Selection {
 func (s *Selection) Remove() *Selection {
 	for _, n := range s.Nodes {
 		if n.Parent != nil {
-			n.Parent.RemoveChild(n)
+			removeChild(s.document, n.Parent, n)
 		}
 	}
 
@@ -282,17 +322,22 @@ func (s *Selection) ReplaceWithSelection(sel *Selection) *Selection {
 // the parsed HTML.
 // It returns the removed elements.
 //
+// It panics if the html cannot be parsed in the context of the elements'
+// parents, see ReplaceWithHtmlE.
+//
 // This follows the same rules as Selection.Append.
 func (s *Selection) ReplaceWithHtml(htmlStr string) *Selection {
-	s.eachNodeHtml(htmlStr, true, func(node *html.Node, nodes []*html.Node) {
-		nextSibling := node.NextSibling
-		for _, n := range nodes {
-			if node.Parent != nil {
-				node.Parent.InsertBefore(n, nextSibling)
-			}
-		}
-	})
-	return s.Remove()
+	return mustManipulate(s.ReplaceWithHtmlE(htmlStr))
+}
+
+// ReplaceWithHtmlE is like ReplaceWithHtml, except that it returns an error
+// instead of panicking if the html cannot be parsed, leaving the document
+// unchanged. See AfterHtmlE for the supported options.
+func (s *Selection) ReplaceWithHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	if _, err := s.AfterHtmlE(htmlStr, opts...); err != nil {
+		return s, err
+	}
+	return s.Remove(), nil
 }
 
 // ReplaceWithNodes replaces each element in the set of matched elements with
@@ -306,16 +351,22 @@ func (s *Selection) ReplaceWithNodes(ns ...*html.Node) *Selection {
 }
 
 // SetHtml sets the html content of each element in the selection to
-// specified html string.
+// specified html string. It panics if the html cannot be parsed in the
+// context of the elements, see SetHtmlE.
 func (s *Selection) SetHtml(htmlStr string) *Selection {
-	for _, context := range s.Nodes {
-		for c := context.FirstChild; c != nil; c = context.FirstChild {
-			context.RemoveChild(c)
+	return mustManipulate(s.SetHtmlE(htmlStr))
+}
+
+// SetHtmlE is like SetHtml, except that it returns an error instead of
+// panicking if the html cannot be parsed, leaving the document unchanged.
+// See AfterHtmlE for the supported options.
+func (s *Selection) SetHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	return s, s.eachNodeHtml(htmlStr, false, opts, func(node *html.Node, nodes []*html.Node) {
+		for c := node.FirstChild; c != nil; c = node.FirstChild {
+			removeChild(s.document, node, c)
 		}
-	}
-	return s.eachNodeHtml(htmlStr, false, func(node *html.Node, nodes []*html.Node) {
 		for _, n := range nodes {
-			node.AppendChild(n)
+			appendChild(s.document, node, n)
 		}
 	})
 }
@@ -370,12 +421,22 @@ func (s *Selection) WrapSelection(sel *Selection) *Selection {
 }
 
 // WrapHtml wraps each element in the set of matched elements inside the inner-
-// most child of the given HTML.
+// most child of the given HTML. It panics if the html cannot be parsed in
+// the context of the elements' parents, see WrapHtmlE.
 //
 // It returns the original set of elements.
 func (s *Selection) WrapHtml(htmlStr string) *Selection {
+	return mustManipulate(s.WrapHtmlE(htmlStr))
+}
+
+// WrapHtmlE is like WrapHtml, except that it returns an error instead of
+// panicking if the html cannot be parsed, leaving the document unchanged.
+// See AfterHtmlE for the supported options.
+func (s *Selection) WrapHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	o := newOptions(opts)
 	nodesMap := make(map[string][]*html.Node)
-	for _, context := range s.Nodes {
+	wraps := make([][]*html.Node, len(s.Nodes))
+	for i, context := range s.Nodes {
 		var parent *html.Node
 		if context.Parent != nil {
 			parent = context.Parent
@@ -384,12 +445,18 @@ func (s *Selection) WrapHtml(htmlStr string) *Selection {
 		}
 		nodes, found := nodesMap[nodeName(parent)]
 		if !found {
-			nodes = parseHtmlWithContext(htmlStr, parent)
+			var err error
+			if nodes, err = o.parseHtml(htmlStr, parent); err != nil {
+				return s, err
+			}
 			nodesMap[nodeName(parent)] = nodes
 		}
-		newSingleSelection(context, s.document).wrapAllNodes(cloneNodes(nodes)...)
+		wraps[i] = cloneNodes(nodes)
 	}
-	return s
+	for i, context := range s.Nodes {
+		newSingleSelection(context, s.document).wrapAllNodes(wraps[i]...)
+	}
+	return s, nil
 }
 
 // WrapNode wraps each element in the set of matched elements inside the inner-
@@ -438,21 +505,31 @@ func (s *Selection) WrapAllSelection(sel *Selection) *Selection {
 
 // WrapAllHtml wraps the given HTML structure around all elements in the set of
 // matched elements. The matched child is cloned before being inserted into the
-// document.
+// document. It panics if the html cannot be parsed in the context of the
+// first element, see WrapAllHtmlE.
 //
 // It returns the original set of elements.
 func (s *Selection) WrapAllHtml(htmlStr string) *Selection {
-	var context *html.Node
-	var nodes []*html.Node
-	if len(s.Nodes) > 0 {
-		context = s.Nodes[0]
-		if context.Parent != nil {
-			nodes = parseHtmlWithContext(htmlStr, context)
-		} else {
-			nodes = parseHtml(htmlStr)
-		}
+	return mustManipulate(s.WrapAllHtmlE(htmlStr))
+}
+
+// WrapAllHtmlE is like WrapAllHtml, except that it returns an error instead
+// of panicking if the html cannot be parsed, leaving the document
+// unchanged. See AfterHtmlE for the supported options.
+func (s *Selection) WrapAllHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	if len(s.Nodes) == 0 {
+		return s, nil
+	}
+
+	context := s.Nodes[0]
+	if context.Parent == nil {
+		context = &html.Node{Type: html.ElementNode}
+	}
+	nodes, err := newOptions(opts).parseHtml(htmlStr, context)
+	if err != nil {
+		return s, err
 	}
-	return s.wrapAllNodes(nodes...)
+	return s.wrapAllNodes(nodes...), nil
 }
 
 func (s *Selection) wrapAllNodes(ns ...*html.Node) *Selection {
@@ -476,8 +553,8 @@ func (s *Selection) WrapAllNode(n *html.Node) *Selection {
 
 	first := s.Nodes[0]
 	if first.Parent != nil {
-		first.Parent.InsertBefore(wrap, first)
-		first.Parent.RemoveChild(first)
+		insertBefore(s.document, first.Parent, wrap, first)
+		removeChild(s.document, first.Parent, first)
 	}
 
 	for c := getFirstChildEl(wrap); c != nil; c = getFirstChildEl(wrap) {
@@ -518,20 +595,21 @@ func (s *Selection) WrapInnerSelection(sel *Selection) *Selection {
 
 // WrapInnerHtml wraps an HTML structure, matched by the given selector, around
 // the content of element in the set of matched elements. The matched child is
-// cloned before being inserted into the document.
+// cloned before being inserted into the document. It panics if the html
+// cannot be parsed in the context of the elements, see WrapInnerHtmlE.
 //
 // It returns the original set of elements.
 func (s *Selection) WrapInnerHtml(htmlStr string) *Selection {
-	nodesMap := make(map[string][]*html.Node)
-	for _, context := range s.Nodes {
-		nodes, found := nodesMap[nodeName(context)]
-		if !found {
-			nodes = parseHtmlWithContext(htmlStr, context)
-			nodesMap[nodeName(context)] = nodes
-		}
-		newSingleSelection(context, s.document).wrapInnerNodes(cloneNodes(nodes)...)
-	}
-	return s
+	return mustManipulate(s.WrapInnerHtmlE(htmlStr))
+}
+
+// WrapInnerHtmlE is like WrapInnerHtml, except that it returns an error
+// instead of panicking if the html cannot be parsed, leaving the document
+// unchanged. See AfterHtmlE for the supported options.
+func (s *Selection) WrapInnerHtmlE(htmlStr string, opts ...Option) (*Selection, error) {
+	return s, s.eachNodeHtml(htmlStr, false, opts, func(node *html.Node, nodes []*html.Node) {
+		newSingleSelection(node, s.document).wrapInnerNodes(nodes...)
+	})
 }
 
 // WrapInnerNode wraps an HTML structure, matched by the given selector, around
@@ -561,24 +639,61 @@ func (s *Selection) wrapInnerNodes(ns ...*html.Node) *Selection {
 	return s
 }
 
-func parseHtml(h string) []*html.Node {
-	// Errors are only returned when the io.Reader returns any error besides
-	// EOF, but strings.Reader never will
-	nodes, err := html.ParseFragment(strings.NewReader(h), &html.Node{Type: html.ElementNode})
+// HtmlError is the error returned by the ...HtmlE manipulation methods when
+// the HTML cannot be parsed in the context of an element, for example
+// because the context is not an element node (such as the Document's root
+// node).
+type HtmlError struct {
+	Context string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *HtmlError) Error() string {
+	return fmt.Sprintf("goquery: failed to parse HTML in context %q: %v", e.Context, e.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (e *HtmlError) Unwrap() error {
+	return e.Err
+}
+
+// mustManipulate returns s, or panics if err is not nil. It is used by the
+// *Html manipulation methods that predate their error-returning variants,
+// and panics with the same string message as they always did.
+func mustManipulate(s *Selection, err error) *Selection {
 	if err != nil {
+		if herr, ok := err.(*HtmlError); ok {
+			err = herr.Err
+		}
 		panic("goquery: failed to parse HTML: " + err.Error())
 	}
-	return nodes
+	return s
 }
 
-func parseHtmlWithContext(h string, context *html.Node) []*html.Node {
-	// Errors are only returned when the io.Reader returns any error besides
-	// EOF, but strings.Reader never will
-	nodes, err := html.ParseFragment(strings.NewReader(h), context)
+// parseHtml parses h as an HTML fragment in the given context, and checks
+// the limits of the options. The parser never fails on malformed HTML, but
+// it fails if the context is not a valid element, and may panic on
+// unexpected contexts, which is returned as an error too.
+func (o *options) parseHtml(h string, context *html.Node) (nodes []*html.Node, err error) {
+	if o.maxBytes > 0 && int64(len(h)) > o.maxBytes {
+		return nil, ErrMaxBytesExceeded
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			nodes, err = nil, &HtmlError{Context: nodeName(context), Err: fmt.Errorf("%v", e)}
+		}
+	}()
+	nodes, err = html.ParseFragmentWithOptions(strings.NewReader(h), context,
+		html.ParseOptionEnableScripting(o.scripting))
 	if err != nil {
-		panic("goquery: failed to parse HTML: " + err.Error())
+		return nil, &HtmlError{Context: nodeName(context), Err: err}
+	}
+	if err := o.checkLimits(nodes, 1); err != nil {
+		return nil, err
 	}
-	return nodes
+	return nodes, nil
 }
 
 // Get the first child that is an ElementNode
@@ -639,7 +754,7 @@ func (s *Selection) manipulateNodes(ns []*html.Node, reverse bool,
 				f(sn, cloneNode(n))
 			} else {
 				if n.Parent != nil {
-					n.Parent.RemoveChild(n)
+					removeChild(s.document, n.Parent, n)
 				}
 				f(sn, n)
 			}
@@ -653,11 +768,14 @@ func (s *Selection) manipulateNodes(ns []*html.Node, reverse bool,
 // The parsed nodes are inserted for each element of the selection.
 // isParent can be used to indicate that the elements of the selection should be treated as the parent for the parsed html.
 // A cache is used to avoid parsing the html multiple times should the elements of the selection result in the same context.
-func (s *Selection) eachNodeHtml(htmlStr string, isParent bool, mergeFn func(n *html.Node, nodes []*html.Node)) *Selection {
+// The html is parsed for all the elements before mergeFn is called, so that the dom is left unchanged if it fails.
+func (s *Selection) eachNodeHtml(htmlStr string, isParent bool, opts []Option, mergeFn func(n *html.Node, nodes []*html.Node)) error {
+	o := newOptions(opts)
 	// cache to avoid parsing the html for the same context multiple times
 	nodeCache := make(map[string][]*html.Node)
+	parsed := make([][]*html.Node, len(s.Nodes))
 	var context *html.Node
-	for _, n := range s.Nodes {
+	for i, n := range s.Nodes {
 		if isParent {
 			context = n.Parent
 		} else {
@@ -666,11 +784,20 @@ func (s *Selection) eachNodeHtml(htmlStr string, isParent bool, mergeFn func(n *
 		if context != nil {
 			nodes, found := nodeCache[nodeName(context)]
 			if !found {
-				nodes = parseHtmlWithContext(htmlStr, context)
+				var err error
+				if nodes, err = o.parseHtml(htmlStr, context); err != nil {
+					return err
+				}
 				nodeCache[nodeName(context)] = nodes
 			}
-			mergeFn(n, cloneNodes(nodes))
+			parsed[i] = cloneNodes(nodes)
 		}
 	}
-	return s
+
+	for i, n := range s.Nodes {
+		if parsed[i] != nil {
+			mergeFn(n, parsed[i])
+		}
+	}
+	return nil
 }
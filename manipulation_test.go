@@ -1,6 +1,7 @@
 package goquery
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -418,6 +419,91 @@ func TestSetHtmlEmpty(t *testing.T) {
 	printSel(t, doc.Selection)
 }
 
+func TestSetHtmlE(t *testing.T) {
+	doc := Doc2Clone()
+	q, err := doc.Find("#main, #foot").SetHtmlE(`<div id="replace">test</div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, q.Nodes, 2)
+	assertLength(t, doc.Find("#replace").Nodes, 2)
+
+	// the document node is not a valid context
+	_, err = doc.SetHtmlE(`<p>x</p>`)
+	if herr, ok := err.(*HtmlError); !ok {
+		t.Errorf("expected an *HtmlError, got %v", err)
+	} else if herr.Unwrap() == nil {
+		t.Error("expected an underlying parse error")
+	}
+	assertLength(t, doc.Find("#replace").Nodes, 2)
+
+	// the non-E variant panics with a string, as it always did
+	defer func() {
+		if msg, ok := recover().(string); !ok || !strings.HasPrefix(msg, "goquery: failed to parse HTML: ") {
+			t.Errorf("expected a string panic, got %v", msg)
+		}
+	}()
+	doc.SetHtml(`<p>x</p>`)
+}
+
+func TestHtmlELimits(t *testing.T) {
+	doc := Doc2Clone()
+	const frag = `<div><p><b>1</b></p></div><div>2</div>`
+
+	cases := []struct {
+		opt  Option
+		want error
+	}{
+		{WithMaxBytes(10), ErrMaxBytesExceeded},
+		{WithMaxNodes(5), ErrMaxNodesExceeded},
+		{WithMaxDepth(3), ErrMaxDepthExceeded},
+	}
+	for _, c := range cases {
+		if _, err := doc.Find("#main").AppendHtmlE(frag, c.opt); err != c.want {
+			t.Errorf("want %v, got %v", c.want, err)
+		}
+	}
+	assertLength(t, doc.Find("#main p").Nodes, 0)
+
+	if _, err := doc.Find("#main").AppendHtmlE(frag, WithMaxBytes(int64(len(frag))), WithMaxNodes(6), WithMaxDepth(4)); err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, doc.Find("#main p").Nodes, 1)
+}
+
+func TestHtmlEUnchangedOnError(t *testing.T) {
+	doc := Doc2Clone()
+	before, _ := doc.Html()
+
+	// the parent of <html> is the document node, an invalid context
+	sel := doc.Find("#main, html")
+	methods := map[string]func(string, ...Option) (*Selection, error){
+		"AfterHtmlE":       sel.AfterHtmlE,
+		"BeforeHtmlE":      sel.BeforeHtmlE,
+		"ReplaceWithHtmlE": sel.ReplaceWithHtmlE,
+		"WrapHtmlE":        sel.WrapHtmlE,
+	}
+	for name, f := range methods {
+		if _, err := f(`<span>x</span>`); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if after, _ := doc.Html(); after != before {
+			t.Errorf("%s: expected the document to be unchanged", name)
+		}
+	}
+
+	for name, f := range map[string]func(string, ...Option) (*Selection, error){
+		"AppendHtmlE":    sel.AppendHtmlE,
+		"PrependHtmlE":   sel.PrependHtmlE,
+		"WrapAllHtmlE":   sel.WrapAllHtmlE,
+		"WrapInnerHtmlE": sel.WrapInnerHtmlE,
+	} {
+		if _, err := f(`<span>x</span>`); err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
+
 func TestSetHtmlContext(t *testing.T) {
 	doc := loadString(t, `
 		<html>
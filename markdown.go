@@ -0,0 +1,429 @@
+package goquery
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// MarkdownOptions configures the output of Markdown. Its zero value uses
+// the defaults documented for each field.
+type MarkdownOptions struct {
+	// BulletMarker is the marker of the items of unordered lists. If empty,
+	// "-" is used.
+	BulletMarker string
+
+	// CodeFence is the fence of code blocks. If empty, "```" is used. A
+	// longer fence is used for code blocks that contain the fence.
+	CodeFence string
+}
+
+// Markdown returns the Markdown (CommonMark, with GitHub Flavored Markdown
+// tables and strikethrough) rendering of the nodes in the Selection. It
+// supports:
+//
+//   - headings, paragraphs, line breaks and horizontal rules;
+//   - emphasis (<em>, <i>), strong emphasis (<strong>, <b>), strikethrough
+//     (<del>, <s>, <strike>) and inline code;
+//   - links and images, with their URLs resolved against the document's
+//     base URL (see Document.BaseURL);
+//   - ordered and unordered lists, including nested lists;
+//   - code blocks, with the language taken from a "language-x" or "lang-x"
+//     class of the <code> or <pre> element;
+//   - blockquotes;
+//   - tables, extracted with NewTable, whose cells are rendered as plain
+//     text.
+//
+// Other elements are rendered as their content, except those that are not
+// rendered by browsers (see InnerText) which are skipped. Text is escaped
+// so that it is not interpreted as Markdown syntax.
+//
+// Like OuterHtml, this is a function and not a method on the Selection,
+// because this is not a jQuery method.
+func Markdown(sel *Selection, opts MarkdownOptions) string {
+	if opts.BulletMarker == "" {
+		opts.BulletMarker = "-"
+	}
+	if opts.CodeFence == "" {
+		opts.CodeFence = "```"
+	}
+	c := &mdConverter{opts: opts, doc: sel.document}
+	if c.doc != nil {
+		c.base = c.doc.BaseURL()
+	}
+	return strings.Join(c.blocks(sel.Nodes), "\n\n")
+}
+
+// mdConverter converts nodes to Markdown.
+type mdConverter struct {
+	opts MarkdownOptions
+	doc  *Document
+	// the base URL of doc, resolved once for all the links
+	base *url.URL
+}
+
+// blocks returns the Markdown blocks of the nodes. Consecutive inline
+// nodes form paragraphs.
+func (c *mdConverter) blocks(nodes []*html.Node) []string {
+	var blocks []string
+	var inline bytes.Buffer
+
+	flush := func() {
+		if p := mdParagraph(inline.String()); p != "" {
+			blocks = append(blocks, p)
+		}
+		inline.Reset()
+	}
+
+	for _, n := range nodes {
+		if n.Type == html.DocumentNode || (n.Type == html.ElementNode && isMdBlock(n)) {
+			flush()
+			if n.Type == html.ElementNode && !isRendered(n) {
+				continue
+			}
+			blocks = append(blocks, c.block(n)...)
+			continue
+		}
+		c.inline(&inline, n)
+	}
+	flush()
+	return blocks
+}
+
+// childBlocks returns the Markdown blocks of the children of n.
+func (c *mdConverter) childBlocks(n *html.Node) []string {
+	var nodes []*html.Node
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		nodes = append(nodes, ch)
+	}
+	return c.blocks(nodes)
+}
+
+// block returns the Markdown blocks of the block node n.
+func (c *mdConverter) block(n *html.Node) []string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		var buf bytes.Buffer
+		c.inlineChildren(&buf, n)
+		text := strings.Join(strings.Fields(strings.Replace(buf.String(), "\\\n", " ", -1)), " ")
+		if text == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + text}
+
+	case atom.Hr:
+		return []string{"---"}
+
+	case atom.Pre:
+		return []string{c.codeBlock(n)}
+
+	case atom.Blockquote:
+		inner := strings.Join(c.childBlocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		return []string{prefixLines(inner, "> ", "> ")}
+
+	case atom.Ul, atom.Ol:
+		if list := c.list(n); list != "" {
+			return []string{list}
+		}
+		return nil
+
+	case atom.Table:
+		if table := c.table(n); table != "" {
+			return []string{table}
+		}
+		return nil
+	}
+
+	// paragraphs and containers
+	return c.childBlocks(n)
+}
+
+// codeBlock returns the fenced code block of the <pre> element n.
+func (c *mdConverter) codeBlock(n *html.Node) string {
+	lang := codeLanguage(n)
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if ch.Type == html.ElementNode && ch.DataAtom == atom.Code {
+			if l := codeLanguage(ch); l != "" {
+				lang = l
+			}
+			break
+		}
+	}
+
+	code := strings.TrimSuffix(newSingleSelection(n, nil).Text(), "\n")
+	fence := c.opts.CodeFence
+	for strings.Contains(code, fence) {
+		fence += fence[:1]
+	}
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// list returns the Markdown list of the <ul> or <ol> element n.
+func (c *mdConverter) list(n *html.Node) string {
+	ordered := n.DataAtom == atom.Ol
+	num := 1
+	if start, ok := getAttributeValue("start", n); ok && ordered {
+		if i, e := strconv.Atoi(strings.TrimSpace(start)); e == nil {
+			num = i
+		}
+	}
+
+	var items []string
+	for _, li := range childElements(n, atom.Li) {
+		if !isRendered(li) {
+			continue
+		}
+		marker := c.opts.BulletMarker + " "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+
+		// items without paragraphs are tight
+		sep := "\n"
+		if findFirstWithMatcher(li, paragraphMatcher) != nil {
+			sep = "\n\n"
+		}
+		content := strings.Join(c.childBlocks(li), sep)
+		items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+	}
+	return strings.Join(items, "\n")
+}
+
+// matches the paragraphs that make list items loose.
+var paragraphMatcher = compileMatcher("p")
+
+// table returns the GFM table of the <table> element n. If the table has no
+// header, its first row is used as header.
+func (c *mdConverter) table(n *html.Node) string {
+	t := NewTable(newSingleSelection(n, nil))
+	rows := append(t.Rows, t.Footer...)
+	header := t.Header
+	if header == nil {
+		if len(rows) == 0 {
+			return ""
+		}
+		header, rows = rows[0], rows[1:]
+	}
+	if len(header) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	writeRow := func(row []string) {
+		buf.WriteString("|")
+		for _, cell := range row {
+			buf.WriteString(" " + strings.Replace(mdEscape(cell), "|", "\\|", -1) + " |")
+		}
+		buf.WriteString("\n")
+	}
+	writeRow(header)
+	buf.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// inline writes the inline Markdown of n to buf.
+func (c *mdConverter) inline(buf *bytes.Buffer, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(mdEscape(rxWhitespace.ReplaceAllString(n.Data, " ")))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if !isRendered(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		buf.WriteString("\\\n")
+	case atom.Em, atom.I:
+		c.wrapInline(buf, n, "*")
+	case atom.Strong, atom.B:
+		c.wrapInline(buf, n, "**")
+	case atom.Del, atom.S, atom.Strike:
+		c.wrapInline(buf, n, "~~")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		buf.WriteString(mdCodeSpan(rxWhitespace.ReplaceAllString(newSingleSelection(n, nil).Text(), " ")))
+	case atom.A:
+		var text bytes.Buffer
+		c.inlineChildren(&text, n)
+		href, ok := getAttributeValue("href", n)
+		if !ok {
+			buf.Write(text.Bytes())
+			return
+		}
+		buf.WriteString("[" + strings.TrimSpace(text.String()) + "](" + c.destination(href, n) + ")")
+	case atom.Img:
+		src, _ := getAttributeValue("src", n)
+		alt, _ := getAttributeValue("alt", n)
+		buf.WriteString("![" + mdEscape(alt) + "](" + c.destination(src, n) + ")")
+	default:
+		c.inlineChildren(buf, n)
+	}
+}
+
+// inlineChildren writes the inline Markdown of the children of n to buf.
+func (c *mdConverter) inlineChildren(buf *bytes.Buffer, n *html.Node) {
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		c.inline(buf, ch)
+	}
+}
+
+// wrapInline writes the inline Markdown of the children of n wrapped in
+// delim, keeping the surrounding whitespace outside of the delimiters.
+func (c *mdConverter) wrapInline(buf *bytes.Buffer, n *html.Node, delim string) {
+	var inner bytes.Buffer
+	c.inlineChildren(&inner, n)
+	s := inner.String()
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		buf.WriteString(s)
+		return
+	}
+	if strings.HasPrefix(s, " ") {
+		buf.WriteString(" ")
+	}
+	buf.WriteString(delim + trimmed + delim)
+	if strings.HasSuffix(s, " ") {
+		buf.WriteString(" ")
+	}
+}
+
+// destination returns the link destination and title of a link or image
+// element n with the URL ref.
+func (c *mdConverter) destination(ref string, n *html.Node) string {
+	if c.doc != nil {
+		if u, e := resolveURL(c.base, ref); e == nil {
+			ref = u.String()
+		}
+	}
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.ContainsAny(ref, " ()<>") {
+		ref = "<" + strings.NewReplacer("<", "\\<", ">", "\\>").Replace(ref) + ">"
+	}
+	if title, ok := getAttributeValue("title", n); ok && title != "" {
+		ref += ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(title) + `"`
+	}
+	return ref
+}
+
+var (
+	rxWhitespace = regexp.MustCompile(`[ \t\n\r\f]+`)
+	// text at the start of a paragraph that would be parsed as a block
+	rxMdBlockStart = regexp.MustCompile(`^(#|>|[-+]( |$)|[-=]+$|(\d+)([.)])( |$))`)
+	rxMdSpaces     = regexp.MustCompile(` {2,}`)
+)
+
+// mdParagraph returns the paragraph of the inline Markdown s, with the
+// whitespace trimmed at the start and end of lines.
+func mdParagraph(s string) string {
+	lines := strings.Split(s, "\\\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(rxMdSpaces.ReplaceAllString(lines[i], " "))
+	}
+	// a line break at the end of a paragraph is ignored
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	for i, l := range lines {
+		if m := rxMdBlockStart.FindStringSubmatch(l); m != nil {
+			if m[3] != "" {
+				// escape the delimiter of ordered list items
+				lines[i] = m[3] + "\\" + l[len(m[3]):]
+			} else {
+				lines[i] = "\\" + l
+			}
+		}
+	}
+	return strings.Join(lines, "\\\n")
+}
+
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`~`, `\~`,
+)
+
+// mdEscape escapes the characters of the text s that would be interpreted
+// as inline Markdown syntax.
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// mdCodeSpan returns the code span of s, using a backtick string longer
+// than any backtick string in s.
+func mdCodeSpan(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// codeLanguage returns the language of a code element set with a
+// "language-x" or "lang-x" class.
+func codeLanguage(n *html.Node) string {
+	class, _ := getAttributeValue("class", n)
+	for _, cl := range strings.Fields(class) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if strings.HasPrefix(cl, prefix) && len(cl) > len(prefix) {
+				return cl[len(prefix):]
+			}
+		}
+	}
+	return ""
+}
+
+// prefixLines prefixes the first line of s with first and the other
+// non-empty lines with rest.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		p := rest
+		if i == 0 {
+			p = first
+		}
+		if l == "" {
+			p = strings.TrimRight(p, " ")
+		}
+		lines[i] = p + l
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isMdBlock returns true if the element n is rendered as a Markdown block.
+func isMdBlock(n *html.Node) bool {
+	return blockBreaks[n.DataAtom] > 0
+}
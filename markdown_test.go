@@ -0,0 +1,88 @@
+package goquery
+
+import (
+	"testing"
+)
+
+func assertMarkdown(t *testing.T, sel *Selection, opts MarkdownOptions, expected string) {
+	t.Helper()
+	if got := Markdown(sel, opts); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	doc := loadStringURL(t, `<html><head><title>T</title></head><body>
+		<h1>The <em>title</em></h1>
+		<p>Some <strong>bold</strong>, <em> italic </em>, <del>old</del> and <code>a`+"`"+`b</code> text.</p>
+		<p>A <a href="/page" title="The &quot;page&quot;">link</a> and an <img src="img.png" alt="image">.<br>
+		Next   line.</p>
+		<h3>Lists</h3>
+		<ul>
+			<li>one</li>
+			<li>two
+				<ol start="3"><li>three</li><li>four</li></ol>
+			</li>
+		</ul>
+		<script>ignored()</script>
+		<blockquote><p>quoted</p><p>twice</p></blockquote>
+		<hr>
+		<div>plain <span>div</span></div>
+	</body></html>`, "https://example.com/docs/")
+
+	assertMarkdown(t, doc.Selection, MarkdownOptions{}, "# The *title*\n\n"+
+		"Some **bold**, *italic* , ~~old~~ and ``a`b`` text.\n\n"+
+		"A [link](https://example.com/page \"The \\\"page\\\"\") and an ![image](https://example.com/docs/img.png).\\\n"+
+		"Next line.\n\n"+
+		"### Lists\n\n"+
+		"- one\n"+
+		"- two\n"+
+		"  3. three\n"+
+		"  4. four\n\n"+
+		"> quoted\n"+
+		">\n"+
+		"> twice\n\n"+
+		"---\n\n"+
+		"plain div")
+}
+
+func TestMarkdownCodeBlock(t *testing.T) {
+	doc := loadString(t, "<pre><code class=\"hl language-go\">func main() {\n\tfmt.Println(\"*\")\n}\n</code></pre>"+
+		"<pre class=\"lang-sh\">echo ```</pre>")
+
+	assertMarkdown(t, doc.Selection, MarkdownOptions{}, "```go\nfunc main() {\n\tfmt.Println(\"*\")\n}\n```\n\n"+
+		"````sh\necho ```\n````")
+	assertMarkdown(t, doc.Find("pre").Last(), MarkdownOptions{CodeFence: "~~~"}, "~~~sh\necho ```\n~~~")
+}
+
+func TestMarkdownTable(t *testing.T) {
+	doc := loadString(t, `<table>
+		<thead><tr><th>Name</th><th>Note</th></tr></thead>
+		<tr><td>a|b</td><td colspan="1"><em>x</em>_y</td></tr>
+	</table>
+	<table><tr><td>no</td><td>header</td></tr><tr><td>1</td><td>2</td></tr></table>`)
+
+	assertMarkdown(t, doc.Selection, MarkdownOptions{}, "| Name | Note |\n| --- | --- |\n| a\\|b | x\\_y |\n\n"+
+		"| no | header |\n| --- | --- |\n| 1 | 2 |")
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	doc := loadString(t, `<p># not a heading</p><p>1. not a list</p><p>- nor this</p><p>*stars* [brackets]</p>`)
+
+	assertMarkdown(t, doc.Selection, MarkdownOptions{}, "\\# not a heading\n\n1\\. not a list\n\n\\- nor this\n\n"+
+		"\\*stars\\* \\[brackets\\]")
+}
+
+func TestMarkdownLooseList(t *testing.T) {
+	doc := loadString(t, `<ul><li><p>first</p><p>more</p></li><li>second</li></ul>`)
+
+	assertMarkdown(t, doc.Find("ul"), MarkdownOptions{BulletMarker: "*"}, "* first\n\n  more\n* second")
+}
+
+func TestMarkdownInlineSelection(t *testing.T) {
+	doc := loadString(t, `<p>A <a href="rel">link</a> <a>anchor</a></p>`)
+
+	// without a document URL, links are kept as-is
+	assertMarkdown(t, doc.Find("a"), MarkdownOptions{}, "[link](rel)anchor")
+	assertMarkdown(t, doc.Find("nothing"), MarkdownOptions{}, "")
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// JSONLDError is the error returned by JSONLD when the content of a script
+// cannot be decoded. Index is the index of the script among the JSON-LD
+// scripts of the document.
+type JSONLDError struct {
+	Index int
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *JSONLDError) Error() string {
+	return fmt.Sprintf("metadata: invalid JSON-LD script %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *JSONLDError) Unwrap() error {
+	return e.Err
+}
+
+// JSONLD returns the decoded content of the <script type="application/ld+json">
+// elements of the document, one value per script, in document order. The
+// values are decoded with encoding/json, so objects are
+// map[string]interface{} and arrays are []interface{}, and are otherwise left
+// as-is (no JSON-LD expansion or compaction is applied, graphs are returned
+// with their @context and @graph keys).
+//
+// Scripts that cannot be decoded are skipped and the error of the first one
+// is returned, as a *JSONLDError, along with the other values.
+func JSONLD(doc *goquery.Document) ([]interface{}, error) {
+	var result []interface{}
+	var err error
+	var i int
+
+	doc.FindMatcher(jsonldMatcher).Each(func(_ int, s *goquery.Selection) {
+		typ, _ := s.Attr("type")
+		if !isJSONLDType(typ) {
+			return
+		}
+		idx := i
+		i++
+
+		var v interface{}
+		if e := json.Unmarshal([]byte(s.Text()), &v); e != nil {
+			if err == nil {
+				err = &JSONLDError{Index: idx, Err: e}
+			}
+			return
+		}
+		result = append(result, v)
+	})
+	return result, err
+}
+
+var jsonldMatcher = goquery.MustCompile("script[type]")
+
+// isJSONLDType returns true if the type attribute's value is the JSON-LD
+// media type, ignoring case and parameters.
+func isJSONLDType(typ string) bool {
+	if i := strings.IndexByte(typ, ';'); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.EqualFold(strings.TrimSpace(typ), "application/ld+json")
+}
@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONLD(t *testing.T) {
+	doc := loadString(t, `<html><head>
+		<script type="application/ld+json">
+			{"@context": "https://schema.org", "@graph": [{"@type": "Person", "name": "A"}]}
+		</script>
+		<script type="text/javascript">var x = 1;</script>
+		<script type="Application/LD+JSON; charset=utf-8">[1, 2]</script>
+	</head></html>`, "")
+
+	v, e := JSONLD(doc)
+	if e != nil {
+		t.Fatal(e)
+	}
+	assertJSON(t, v, `[{"@context":"https://schema.org","@graph":[{"@type":"Person","name":"A"}]},[1,2]]`)
+}
+
+func TestJSONLDInvalid(t *testing.T) {
+	doc := loadString(t, `<html><head>
+		<script type="application/ld+json">{"a": 1}</script>
+		<script type="application/ld+json">{"b": </script>
+		<script type="application/ld+json">{"c": 3}</script>
+		<script type="application/ld+json">nope</script>
+	</head></html>`, "")
+
+	v, e := JSONLD(doc)
+	assertJSON(t, v, `[{"a":1},{"c":3}]`)
+
+	var je *JSONLDError
+	if !errors.As(e, &je) {
+		t.Fatalf("expected a *JSONLDError, got %v", e)
+	}
+	if je.Index != 1 {
+		t.Errorf("expected index 1, got %d", je.Index)
+	}
+}
+
+func TestJSONLDNone(t *testing.T) {
+	doc := loadString(t, `<p>nothing</p>`, "")
+	v, e := JSONLD(doc)
+	if v != nil || e != nil {
+		t.Errorf("expected nil, nil, got %v, %v", v, e)
+	}
+}
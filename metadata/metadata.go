@@ -0,0 +1,84 @@
+// Package metadata extracts the structured metadata embedded in HTML
+// documents loaded with goquery: JSON-LD scripts, microdata items, RDFa Lite
+// resources and the OpenGraph and Twitter card meta tags.
+//
+// Relative URLs found in the metadata (e.g. an itemid, an og:image or the
+// href of a microdata property) are resolved against the document's base URL,
+// see goquery.Document.BaseURL.
+package metadata
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Metadata holds all the metadata of a document.
+type Metadata struct {
+	JSONLD    []interface{}
+	Microdata []*Item
+	RDFa      []*Resource
+	OpenGraph *OpenGraph
+	Twitter   *TwitterCard
+}
+
+// Extract returns all the metadata of the document. If some JSON-LD script
+// cannot be decoded, the returned error is the one returned by JSONLD, but
+// the rest of the metadata is still extracted.
+func Extract(doc *goquery.Document) (*Metadata, error) {
+	jsonld, e := JSONLD(doc)
+	return &Metadata{
+		JSONLD:    jsonld,
+		Microdata: Microdata(doc),
+		RDFa:      RDFa(doc),
+		OpenGraph: ExtractOpenGraph(doc),
+		Twitter:   ExtractTwitterCard(doc),
+	}, e
+}
+
+// absURL resolves ref against base, the document's base URL, which may be
+// nil. If ref cannot be parsed as a URL, it is returned unchanged.
+func absURL(base *url.URL, ref string) string {
+	u, e := url.Parse(strings.TrimSpace(ref))
+	if e != nil {
+		return ref
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+// attr returns the value of the attribute of n.
+func attr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// hasAttr returns true if n has the attribute.
+func hasAttr(n *html.Node, name string) bool {
+	_, ok := attr(n, name)
+	return ok
+}
+
+// textOf returns the text content of n, like Selection.Text.
+func textOf(n *html.Node) string {
+	var buf strings.Builder
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			buf.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(n)
+	return buf.String()
+}
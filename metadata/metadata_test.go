@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func loadString(t *testing.T, doc, u string) *goquery.Document {
+	var opts []goquery.Option
+	if u != "" {
+		pu, e := url.Parse(u)
+		if e != nil {
+			t.Fatal(e)
+		}
+		opts = append(opts, goquery.WithURL(pu))
+	}
+	d, e := goquery.NewDocumentFromReaderWithOptions(strings.NewReader(doc), opts...)
+	if e != nil {
+		t.Fatal(e)
+	}
+	return d
+}
+
+// assertJSON asserts that the JSON encoding of v is expected.
+func assertJSON(t *testing.T, v interface{}, expected string) {
+	t.Helper()
+	b, e := json.Marshal(v)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if string(b) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	doc := loadString(t, `<html><head>
+		<meta property="og:title" content="Title">
+		<meta name="twitter:card" content="summary">
+		<script type="application/ld+json">{"@type": "Thing"}</script>
+		<script type="application/ld+json">{invalid</script>
+	</head><body>
+		<div itemscope><span itemprop="a">b</span></div>
+		<div vocab="https://schema.org/" typeof="Thing"><span property="name">n</span></div>
+	</body></html>`, "")
+
+	md, e := Extract(doc)
+	if e == nil {
+		t.Error("expected an error for the invalid JSON-LD script")
+	}
+	if len(md.JSONLD) != 1 || len(md.Microdata) != 1 || len(md.RDFa) != 1 {
+		t.Errorf("unexpected metadata: %+v", md)
+	}
+	if md.OpenGraph == nil || md.OpenGraph.Title != "Title" {
+		t.Errorf("unexpected OpenGraph: %+v", md.OpenGraph)
+	}
+	if md.Twitter == nil || md.Twitter.Card != "summary" {
+		t.Errorf("unexpected Twitter card: %+v", md.Twitter)
+	}
+}
@@ -0,0 +1,217 @@
+package metadata
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Item is a microdata item, an element with an itemscope attribute. Its
+// JSON encoding follows the JSON format of the HTML microdata
+// specification.
+type Item struct {
+	// Types holds the tokens of the itemtype attribute.
+	Types []string `json:"type,omitempty"`
+
+	// ID holds the value of the itemid attribute, resolved as a URL.
+	ID string `json:"id,omitempty"`
+
+	// Properties holds the values of the properties of the item, by name.
+	// The values are either strings or, for properties that are items
+	// themselves, *Item.
+	Properties map[string][]interface{} `json:"properties"`
+}
+
+// Microdata returns the top-level microdata items of the document, those
+// with an itemscope attribute and no itemprop attribute, in document order.
+//
+// The properties of the items are found as described by the HTML microdata
+// specification, including those of the elements referenced by the itemref
+// attribute. The value of a property is:
+//
+//   - the item for elements with an itemscope attribute;
+//   - the content attribute for <meta> elements;
+//   - the src attribute, resolved as a URL, for <audio>, <embed>, <iframe>,
+//     <img>, <source>, <track> and <video> elements;
+//   - the href attribute, resolved as a URL, for <a>, <area> and <link>
+//     elements;
+//   - the data attribute, resolved as a URL, for <object> elements;
+//   - the value attribute for <data> and <meter> elements;
+//   - the datetime attribute for <time> elements that have one;
+//   - the text of the element, with leading and trailing whitespace
+//     removed, otherwise.
+//
+// Items that are (directly or indirectly) properties of themselves, which can
+// happen through itemref, are not added as values.
+func Microdata(doc *goquery.Document) []*Item {
+	md := newMicrodataParser(doc)
+
+	var items []*Item
+	doc.FindMatcher(itemscopeMatcher).Each(func(_ int, s *goquery.Selection) {
+		if _, ok := s.Attr("itemprop"); !ok {
+			items = append(items, md.item(s.Get(0), nil))
+		}
+	})
+	return items
+}
+
+var itemscopeMatcher = goquery.MustCompile("[itemscope]")
+
+// microdataParser holds the state needed to extract the microdata items of
+// a document.
+type microdataParser struct {
+	base  *url.URL
+	ids   map[string]*html.Node
+	order map[*html.Node]int
+}
+
+func newMicrodataParser(doc *goquery.Document) *microdataParser {
+	md := &microdataParser{
+		base:  doc.BaseURL(),
+		ids:   make(map[string]*html.Node),
+		order: make(map[*html.Node]int),
+	}
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			md.order[n] = len(md.order)
+			if id, ok := attr(n, "id"); ok && id != "" {
+				if _, dup := md.ids[id]; !dup {
+					md.ids[id] = n
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	for _, n := range doc.Nodes {
+		f(n)
+	}
+	return md
+}
+
+// item returns the item of the element n. The ancestors are the items being
+// built that contain this one, used to detect cycles.
+func (md *microdataParser) item(n *html.Node, ancestors map[*html.Node]bool) *Item {
+	it := &Item{Properties: make(map[string][]interface{})}
+	if typ, ok := attr(n, "itemtype"); ok {
+		it.Types = strings.Fields(typ)
+	}
+	if id, ok := attr(n, "itemid"); ok {
+		it.ID = absURL(md.base, id)
+	}
+
+	anc := make(map[*html.Node]bool, len(ancestors)+1)
+	for k := range ancestors {
+		anc[k] = true
+	}
+	anc[n] = true
+
+	for _, p := range md.properties(n) {
+		var val interface{}
+		if hasAttr(p, "itemscope") {
+			if anc[p] {
+				continue
+			}
+			val = md.item(p, anc)
+		} else {
+			val = md.value(p)
+		}
+
+		prop, _ := attr(p, "itemprop")
+		seen := make(map[string]bool)
+		for _, name := range strings.Fields(prop) {
+			if !seen[name] {
+				seen[name] = true
+				it.Properties[name] = append(it.Properties[name], val)
+			}
+		}
+	}
+	return it
+}
+
+// properties returns the property elements of the item root, in document
+// order, following the algorithm of the microdata specification.
+func (md *microdataParser) properties(root *html.Node) []*html.Node {
+	var results, pending []*html.Node
+	memory := map[*html.Node]bool{root: true}
+
+	pending = appendChildElements(pending, root)
+	if ref, ok := attr(root, "itemref"); ok {
+		for _, id := range strings.Fields(ref) {
+			if n := md.ids[id]; n != nil {
+				pending = append(pending, n)
+			}
+		}
+	}
+
+	for len(pending) > 0 {
+		cur := pending[0]
+		pending = pending[1:]
+		if memory[cur] {
+			continue
+		}
+		memory[cur] = true
+
+		if !hasAttr(cur, "itemscope") {
+			pending = appendChildElements(pending, cur)
+		}
+		if prop, ok := attr(cur, "itemprop"); ok && len(strings.Fields(prop)) > 0 {
+			results = append(results, cur)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return md.order[results[i]] < md.order[results[j]]
+	})
+	return results
+}
+
+// value returns the value of the property element n, which is not an item.
+func (md *microdataParser) value(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.Meta:
+		v, _ := attr(n, "content")
+		return v
+	case atom.Audio, atom.Embed, atom.Iframe, atom.Img, atom.Source, atom.Track, atom.Video:
+		return md.urlValue(n, "src")
+	case atom.A, atom.Area, atom.Link:
+		return md.urlValue(n, "href")
+	case atom.Object:
+		return md.urlValue(n, "data")
+	case atom.Data, atom.Meter:
+		v, _ := attr(n, "value")
+		return v
+	case atom.Time:
+		if v, ok := attr(n, "datetime"); ok {
+			return v
+		}
+	}
+	return strings.TrimSpace(textOf(n))
+}
+
+// urlValue returns the value of the URL attribute of n, resolved against
+// the document's base URL, or an empty string if the attribute is absent.
+func (md *microdataParser) urlValue(n *html.Node, name string) string {
+	v, ok := attr(n, name)
+	if !ok {
+		return ""
+	}
+	return absURL(md.base, v)
+}
+
+// appendChildElements appends the child elements of n to nodes.
+func appendChildElements(nodes []*html.Node, n *html.Node) []*html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,67 @@
+package metadata
+
+import "testing"
+
+func TestMicrodata(t *testing.T) {
+	doc := loadString(t, `<html><body>
+		<div itemscope itemtype="https://schema.org/Product" itemid="/products/1">
+			<h1 itemprop="name"> Widget </h1>
+			<img itemprop="image" src="widget.png">
+			<a itemprop="url" href="/w">link</a>
+			<meta itemprop="sku" content="W1">
+			<data itemprop="weight" value="10">ten</data>
+			<time itemprop="releaseDate" datetime="2020-01-02">Jan 2</time>
+			<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+				<span itemprop="price priceValue price">9.99</span>
+			</div>
+		</div>
+		<span itemprop="orphan">not in an item</span>
+	</body></html>`, "https://example.com/shop/")
+
+	assertJSON(t, Microdata(doc), `[{"type":["https://schema.org/Product"],`+
+		`"id":"https://example.com/products/1","properties":{`+
+		`"image":["https://example.com/shop/widget.png"],`+
+		`"name":["Widget"],`+
+		`"offers":[{"type":["https://schema.org/Offer"],"properties":{"price":["9.99"],"priceValue":["9.99"]}}],`+
+		`"releaseDate":["2020-01-02"],`+
+		`"sku":["W1"],`+
+		`"url":["https://example.com/w"],`+
+		`"weight":["10"]}}]`)
+}
+
+func TestMicrodataItemref(t *testing.T) {
+	doc := loadString(t, `<html><body>
+		<div itemscope itemref="extra missing"><span itemprop="a">1</span></div>
+		<p id="extra"><span itemprop="b">2</span><span itemprop="c">3</span></p>
+	</body></html>`, "")
+
+	assertJSON(t, Microdata(doc), `[{"properties":{"a":["1"],"b":["2"],"c":["3"]}}]`)
+}
+
+func TestMicrodataItemrefOrder(t *testing.T) {
+	// properties are in document order, even when referenced
+	doc := loadString(t, `<html><body>
+		<p id="first"><span itemprop="a">1</span></p>
+		<div itemscope itemref="first"><span itemprop="a">2</span></div>
+	</body></html>`, "")
+
+	assertJSON(t, Microdata(doc), `[{"properties":{"a":["1","2"]}}]`)
+}
+
+func TestMicrodataCycle(t *testing.T) {
+	doc := loadString(t, `<html><body>
+		<div itemscope id="x">
+			<div itemprop="child" itemscope itemref="x"><span itemprop="name">c</span></div>
+		</div>
+	</body></html>`, "")
+
+	// the child references its parent, which is not added as its property
+	assertJSON(t, Microdata(doc), `[{"properties":{"child":[{"properties":{"name":["c"]}}]}}]`)
+}
+
+func TestMicrodataNone(t *testing.T) {
+	doc := loadString(t, `<p itemprop="a">nothing</p>`, "")
+	if items := Microdata(doc); items != nil {
+		t.Errorf("expected nil, got %v", items)
+	}
+}
@@ -0,0 +1,222 @@
+package metadata
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// OpenGraph holds the OpenGraph properties of a document, set with
+// <meta property="og:..." content="..."> elements.
+type OpenGraph struct {
+	Title       string
+	Type        string
+	URL         string
+	Description string
+	SiteName    string
+	Locale      string
+	Determiner  string
+
+	Images []*OpenGraphMedia
+	Videos []*OpenGraphMedia
+	Audios []*OpenGraphMedia
+
+	// Properties holds the raw values of all the og: properties of the
+	// document, keyed by property name without the "og:" prefix (e.g.
+	// "image:width"), in document order. It also holds those without a
+	// corresponding field.
+	Properties map[string][]string
+}
+
+// OpenGraphMedia holds the properties of an image, video or audio of an
+// OpenGraph object. For audios, only URL, SecureURL and Type are set.
+type OpenGraphMedia struct {
+	URL       string
+	SecureURL string
+	Type      string
+	Width     int
+	Height    int
+	Alt       string
+}
+
+// TwitterCard holds the Twitter card properties of a document, set with
+// <meta name="twitter:..." content="..."> elements.
+type TwitterCard struct {
+	Card        string
+	Site        string
+	Creator     string
+	Title       string
+	Description string
+	Image       string
+	ImageAlt    string
+
+	// Properties holds the raw values of all the twitter: properties of the
+	// document, keyed by property name without the "twitter:" prefix (e.g.
+	// "image:alt"), in document order. It also holds those without a
+	// corresponding field.
+	Properties map[string][]string
+}
+
+// ExtractOpenGraph returns the OpenGraph properties of the document. The
+// properties are read from the property attribute of <meta> elements, or
+// from their name attribute as some pages do. The URL properties are
+// resolved against the document's base URL.
+//
+// As per the OpenGraph protocol, the structured properties of images,
+// videos and audios (e.g. og:image:width) apply to the last og:image,
+// og:video or og:audio declared before them. The first value of the other
+// properties is used.
+//
+// It returns nil if the document has no OpenGraph property.
+func ExtractOpenGraph(doc *goquery.Document) *OpenGraph {
+	props := metaProperties(doc, "og:")
+	if len(props) == 0 {
+		return nil
+	}
+
+	base := doc.BaseURL()
+	og := &OpenGraph{Properties: make(map[string][]string)}
+	// the last image, video and audio declared
+	cur := make(map[string]*OpenGraphMedia)
+
+	for _, p := range props {
+		og.Properties[p.name] = append(og.Properties[p.name], p.content)
+
+		kind, sub := p.name, ""
+		if i := strings.IndexByte(p.name, ':'); i >= 0 {
+			kind, sub = p.name[:i], p.name[i+1:]
+		}
+
+		switch kind {
+		case "image", "video", "audio":
+			m := cur[kind]
+			if sub == "" || sub == "url" || m == nil {
+				m = &OpenGraphMedia{}
+				cur[kind] = m
+				switch kind {
+				case "image":
+					og.Images = append(og.Images, m)
+				case "video":
+					og.Videos = append(og.Videos, m)
+				case "audio":
+					og.Audios = append(og.Audios, m)
+				}
+			}
+			m.set(base, sub, p.content)
+
+		case "title":
+			setFirst(&og.Title, p.content)
+		case "type":
+			setFirst(&og.Type, p.content)
+		case "url":
+			setFirst(&og.URL, absURL(base, p.content))
+		case "description":
+			setFirst(&og.Description, p.content)
+		case "site_name":
+			setFirst(&og.SiteName, p.content)
+		case "locale":
+			if sub == "" {
+				setFirst(&og.Locale, p.content)
+			}
+		case "determiner":
+			setFirst(&og.Determiner, p.content)
+		}
+	}
+	return og
+}
+
+// set sets the structured property sub of the media ("" or "url" for the
+// URL).
+func (m *OpenGraphMedia) set(base *url.URL, sub, content string) {
+	switch sub {
+	case "", "url":
+		m.URL = absURL(base, content)
+	case "secure_url":
+		m.SecureURL = absURL(base, content)
+	case "type":
+		m.Type = content
+	case "width":
+		m.Width, _ = strconv.Atoi(strings.TrimSpace(content))
+	case "height":
+		m.Height, _ = strconv.Atoi(strings.TrimSpace(content))
+	case "alt":
+		m.Alt = content
+	}
+}
+
+// ExtractTwitterCard returns the Twitter card properties of the document.
+// The properties are read from the name attribute of <meta> elements, or
+// from their property attribute as some pages do. The first value of each
+// property is used, and the image URL is resolved against the document's
+// base URL.
+//
+// It returns nil if the document has no Twitter card property.
+func ExtractTwitterCard(doc *goquery.Document) *TwitterCard {
+	props := metaProperties(doc, "twitter:")
+	if len(props) == 0 {
+		return nil
+	}
+
+	base := doc.BaseURL()
+	tc := &TwitterCard{Properties: make(map[string][]string)}
+	for _, p := range props {
+		tc.Properties[p.name] = append(tc.Properties[p.name], p.content)
+
+		switch p.name {
+		case "card":
+			setFirst(&tc.Card, p.content)
+		case "site":
+			setFirst(&tc.Site, p.content)
+		case "creator":
+			setFirst(&tc.Creator, p.content)
+		case "title":
+			setFirst(&tc.Title, p.content)
+		case "description":
+			setFirst(&tc.Description, p.content)
+		case "image", "image:src":
+			setFirst(&tc.Image, absURL(base, p.content))
+		case "image:alt":
+			setFirst(&tc.ImageAlt, p.content)
+		}
+	}
+	return tc
+}
+
+// metaProperty is a property set with a <meta> element.
+type metaProperty struct {
+	name    string
+	content string
+}
+
+// metaProperties returns the properties of the <meta> elements whose
+// property or name attribute starts with prefix, with the prefix removed
+// from their names, in document order.
+func metaProperties(doc *goquery.Document, prefix string) []metaProperty {
+	var props []metaProperty
+	doc.FindMatcher(metaContentMatcher).Each(func(_ int, s *goquery.Selection) {
+		for _, key := range []string{"property", "name"} {
+			name, _ := s.Attr(key)
+			name = strings.ToLower(strings.TrimSpace(name))
+			if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+				content, _ := s.Attr("content")
+				props = append(props, metaProperty{
+					name:    strings.TrimPrefix(name, prefix),
+					content: strings.TrimSpace(content),
+				})
+				return
+			}
+		}
+	})
+	return props
+}
+
+var metaContentMatcher = goquery.MustCompile("meta[content]")
+
+// setFirst sets *dst to v if it is not already set.
+func setFirst(dst *string, v string) {
+	if *dst == "" {
+		*dst = v
+	}
+}
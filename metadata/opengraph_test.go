@@ -0,0 +1,109 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractOpenGraph(t *testing.T) {
+	doc := loadString(t, `<html><head>
+		<meta property="og:title" content=" The Title ">
+		<meta property="og:title" content="Second title">
+		<meta property="og:type" content="article">
+		<meta property="og:url" content="/article">
+		<meta property="og:description" content="Desc">
+		<meta property="og:site_name" content="Site">
+		<meta property="og:locale" content="en_US">
+		<meta property="og:locale:alternate" content="fr_FR">
+		<meta property="og:image" content="a.png">
+		<meta property="og:image:width" content="300">
+		<meta property="og:image:height" content="bad">
+		<meta property="og:image:alt" content="A">
+		<meta property="og:image:url" content="b.png">
+		<meta property="og:image:secure_url" content="https://cdn.example.com/b.png">
+		<meta property="og:image:type" content="image/png">
+		<meta property="og:video:width" content="640">
+		<meta name="og:audio" content="c.mp3">
+		<meta property="article:author" content="ignored">
+	</head></html>`, "https://example.com/blog/")
+
+	og := ExtractOpenGraph(doc)
+	if og == nil {
+		t.Fatal("expected OpenGraph, got nil")
+	}
+	if og.Title != "The Title" || og.Type != "article" || og.Description != "Desc" ||
+		og.SiteName != "Site" || og.Locale != "en_US" {
+		t.Errorf("unexpected OpenGraph: %+v", og)
+	}
+	if og.URL != "https://example.com/article" {
+		t.Errorf("unexpected URL: %s", og.URL)
+	}
+
+	images := []*OpenGraphMedia{
+		{URL: "https://example.com/blog/a.png", Width: 300, Alt: "A"},
+		{URL: "https://example.com/blog/b.png", SecureURL: "https://cdn.example.com/b.png", Type: "image/png"},
+	}
+	if !reflect.DeepEqual(og.Images, images) {
+		t.Errorf("unexpected images: %+v, %+v", og.Images[0], og.Images[1])
+	}
+	if len(og.Videos) != 1 || og.Videos[0].Width != 640 || og.Videos[0].URL != "" {
+		t.Errorf("unexpected videos: %+v", og.Videos)
+	}
+	if len(og.Audios) != 1 || og.Audios[0].URL != "https://example.com/blog/c.mp3" {
+		t.Errorf("unexpected audios: %+v", og.Audios)
+	}
+
+	if titles := og.Properties["title"]; !reflect.DeepEqual(titles, []string{"The Title", "Second title"}) {
+		t.Errorf("unexpected title properties: %q", titles)
+	}
+	if alt := og.Properties["locale:alternate"]; !reflect.DeepEqual(alt, []string{"fr_FR"}) {
+		t.Errorf("unexpected locale:alternate properties: %q", alt)
+	}
+}
+
+func TestExtractTwitterCard(t *testing.T) {
+	doc := loadString(t, `<html><head>
+		<meta name="twitter:card" content="summary_large_image">
+		<meta name="twitter:site" content="@site">
+		<meta name="twitter:creator" content="@me">
+		<meta property="twitter:title" content="Title">
+		<meta name="twitter:description" content="Desc">
+		<meta name="twitter:image" content="/i.png">
+		<meta name="twitter:image:alt" content="Alt">
+		<meta name="twitter:label1" content="Reading time">
+	</head></html>`, "https://example.com/a/b")
+
+	tc := ExtractTwitterCard(doc)
+	expected := &TwitterCard{
+		Card:        "summary_large_image",
+		Site:        "@site",
+		Creator:     "@me",
+		Title:       "Title",
+		Description: "Desc",
+		Image:       "https://example.com/i.png",
+		ImageAlt:    "Alt",
+		Properties: map[string][]string{
+			"card":        {"summary_large_image"},
+			"site":        {"@site"},
+			"creator":     {"@me"},
+			"title":       {"Title"},
+			"description": {"Desc"},
+			"image":       {"/i.png"},
+			"image:alt":   {"Alt"},
+			"label1":      {"Reading time"},
+		},
+	}
+	if !reflect.DeepEqual(tc, expected) {
+		t.Errorf("expected %+v, got %+v", expected, tc)
+	}
+}
+
+func TestExtractNone(t *testing.T) {
+	doc := loadString(t, `<html><head><meta name="description" content="x"></head></html>`, "")
+	if og := ExtractOpenGraph(doc); og != nil {
+		t.Errorf("expected nil OpenGraph, got %+v", og)
+	}
+	if tc := ExtractTwitterCard(doc); tc != nil {
+		t.Errorf("expected nil Twitter card, got %+v", tc)
+	}
+}
@@ -0,0 +1,177 @@
+package metadata
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Resource is an RDFa Lite resource, an element with a typeof attribute.
+type Resource struct {
+	// Vocab holds the vocabulary in effect for the element, as set by the
+	// vocab attribute of the element or of its closest ancestor that has one.
+	Vocab string `json:"vocab,omitempty"`
+
+	// Types holds the expanded tokens of the typeof attribute.
+	Types []string `json:"type,omitempty"`
+
+	// ID holds the value of the resource attribute, resolved as a URL.
+	ID string `json:"id,omitempty"`
+
+	// Properties holds the values of the properties of the resource, keyed
+	// by expanded property name. The values are either strings or, for
+	// properties that are resources themselves, *Resource.
+	Properties map[string][]interface{} `json:"properties"`
+}
+
+// RDFa returns the top-level RDFa Lite resources of the document, those
+// that are not the value of a property of another resource, in document
+// order.
+//
+// The names of the types and properties are expanded to IRIs: terms are
+// prefixed with the vocabulary in effect (e.g. "name" with
+// vocab="https://schema.org/" is "https://schema.org/name") and compact IRIs
+// using a prefix declared with the prefix attribute are expanded (e.g.
+// "dc:title" with prefix="dc: http://purl.org/dc/terms/" is
+// "http://purl.org/dc/terms/title"). Other names are kept as-is.
+//
+// The value of a property is the resource itself if the element has a
+// typeof attribute, otherwise it is, in order of precedence: the content
+// attribute, the resource, href or src attribute resolved as a URL, the
+// datetime attribute of <time> elements, or the text of the element with
+// leading and trailing whitespace removed. Properties that are not
+// inside a resource are ignored.
+func RDFa(doc *goquery.Document) []*Resource {
+	p := &rdfaParser{base: doc.BaseURL()}
+	for _, n := range doc.Nodes {
+		p.walk(n, rdfaContext{})
+	}
+	return p.resources
+}
+
+// rdfaParser holds the state needed to extract the RDFa Lite resources of a
+// document.
+type rdfaParser struct {
+	base      *url.URL
+	resources []*Resource
+}
+
+// rdfaContext is the evaluation context of an element, inherited from its
+// ancestors.
+type rdfaContext struct {
+	vocab    string
+	prefixes map[string]string
+	subject  *Resource
+}
+
+func (p *rdfaParser) walk(n *html.Node, ctx rdfaContext) {
+	if n.Type == html.ElementNode {
+		ctx = p.element(n, ctx)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c, ctx)
+	}
+}
+
+// element processes the element n and returns the context of its children.
+func (p *rdfaParser) element(n *html.Node, ctx rdfaContext) rdfaContext {
+	if v, ok := attr(n, "vocab"); ok {
+		ctx.vocab = strings.TrimSpace(v)
+	}
+	if v, ok := attr(n, "prefix"); ok {
+		ctx.prefixes = parsePrefixes(v, ctx.prefixes)
+	}
+
+	prop, hasProp := attr(n, "property")
+	typeof, hasType := attr(n, "typeof")
+
+	var res *Resource
+	if hasType {
+		res = &Resource{Vocab: ctx.vocab, Properties: make(map[string][]interface{})}
+		for _, t := range strings.Fields(typeof) {
+			res.Types = append(res.Types, ctx.expand(t))
+		}
+		if id, ok := attr(n, "resource"); ok {
+			res.ID = absURL(p.base, id)
+		}
+	}
+
+	names := strings.Fields(prop)
+	if hasProp && len(names) > 0 && ctx.subject != nil {
+		var val interface{}
+		if res != nil {
+			val = res
+		} else {
+			val = p.value(n)
+		}
+		for _, name := range names {
+			name = ctx.expand(name)
+			ctx.subject.Properties[name] = append(ctx.subject.Properties[name], val)
+		}
+	} else if res != nil {
+		p.resources = append(p.resources, res)
+	}
+
+	if res != nil {
+		ctx.subject = res
+	}
+	return ctx
+}
+
+// value returns the value of the property element n, which is not a
+// resource.
+func (p *rdfaParser) value(n *html.Node) string {
+	if v, ok := attr(n, "content"); ok {
+		return v
+	}
+	for _, name := range []string{"resource", "href", "src"} {
+		if v, ok := attr(n, name); ok {
+			return absURL(p.base, v)
+		}
+	}
+	if n.DataAtom == atom.Time {
+		if v, ok := attr(n, "datetime"); ok {
+			return v
+		}
+	}
+	return strings.TrimSpace(textOf(n))
+}
+
+// expand returns the IRI of the type or property name.
+func (ctx rdfaContext) expand(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		if iri, ok := ctx.prefixes[strings.ToLower(name[:i])]; ok {
+			return iri + name[i+1:]
+		}
+		return name
+	}
+	if ctx.vocab != "" {
+		return ctx.vocab + name
+	}
+	return name
+}
+
+// parsePrefixes parses the value of a prefix attribute, a list of
+// "prefix: iri" pairs, and returns the prefix mappings inherited from the
+// parent updated with those pairs. The parent's map is not modified.
+func parsePrefixes(v string, parent map[string]string) map[string]string {
+	m := make(map[string]string, len(parent))
+	for k, iri := range parent {
+		m[k] = iri
+	}
+
+	fields := strings.Fields(v)
+	for i := 0; i+1 < len(fields); i += 2 {
+		pfx := fields[i]
+		if !strings.HasSuffix(pfx, ":") || len(pfx) == 1 {
+			// invalid pair, resynchronize on the next token
+			i--
+			continue
+		}
+		m[strings.ToLower(strings.TrimSuffix(pfx, ":"))] = fields[i+1]
+	}
+	return m
+}
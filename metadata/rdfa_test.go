@@ -0,0 +1,58 @@
+package metadata
+
+import "testing"
+
+func TestRDFa(t *testing.T) {
+	doc := loadString(t, `<html><body>
+		<div vocab="https://schema.org/" typeof="Person" resource="#me">
+			<span property="name"> Alice </span>
+			<a property="url" href="/alice">home</a>
+			<a property="nickname" href="/x" content="Al">x</a>
+			<img property="image" src="a.png">
+			<meta property="gender" content="female">
+			<time property="birthDate" datetime="1990-01-01">Jan 1</time>
+			<div property="address" typeof="PostalAddress">
+				<span property="addressLocality">Paris</span>
+			</div>
+		</div>
+		<span property="name">outside</span>
+	</body></html>`, "https://example.com/people/")
+
+	assertJSON(t, RDFa(doc), `[{"vocab":"https://schema.org/","type":["https://schema.org/Person"],`+
+		`"id":"https://example.com/people/#me","properties":{`+
+		`"https://schema.org/address":[{"vocab":"https://schema.org/","type":["https://schema.org/PostalAddress"],`+
+		`"properties":{"https://schema.org/addressLocality":["Paris"]}}],`+
+		`"https://schema.org/birthDate":["1990-01-01"],`+
+		`"https://schema.org/gender":["female"],`+
+		`"https://schema.org/image":["https://example.com/people/a.png"],`+
+		`"https://schema.org/name":["Alice"],`+
+		`"https://schema.org/nickname":["Al"],`+
+		`"https://schema.org/url":["https://example.com/alice"]}}]`)
+}
+
+func TestRDFaPrefix(t *testing.T) {
+	doc := loadString(t, `<html><body>
+		<div prefix="dc: http://purl.org/dc/terms/ bad schema: https://schema.org/" typeof="schema:Book">
+			<span property="dc:title DC:creator">T</span>
+			<span property="ex:other">x</span>
+			<span property="term">y</span>
+		</div>
+	</body></html>`, "")
+
+	assertJSON(t, RDFa(doc), `[{"type":["https://schema.org/Book"],"properties":{`+
+		`"ex:other":["x"],`+
+		`"http://purl.org/dc/terms/creator":["T"],`+
+		`"http://purl.org/dc/terms/title":["T"],`+
+		`"term":["y"]}}]`)
+}
+
+func TestRDFaSeparateResources(t *testing.T) {
+	doc := loadString(t, `<html><body vocab="https://schema.org/">
+		<div typeof="A"><div typeof="B"><span property="p">1</span></div></div>
+	</body></html>`, "")
+
+	// a typeof without property is a new top-level resource
+	assertJSON(t, RDFa(doc), `[`+
+		`{"vocab":"https://schema.org/","type":["https://schema.org/A"],"properties":{}},`+
+		`{"vocab":"https://schema.org/","type":["https://schema.org/B"],"properties":{"https://schema.org/p":["1"]}}]`)
+}
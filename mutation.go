@@ -0,0 +1,245 @@
+package goquery
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/net/html"
+)
+
+// The changes made to a document by the Selection methods go through the
+// functions of this file, which apply them to the nodes and record them in
+// the state of the Selection's Document: its transactions, its observers,
+// its index and the numbering of its nodes.
+
+// mutationKind is the kind of a change made to a document tree.
+type mutationKind int
+
+const (
+	childListMutation mutationKind = iota
+	attributesMutation
+	characterDataMutation
+)
+
+// mutation is a change made to a document tree, with what is needed to
+// undo it.
+type mutation struct {
+	kind mutationKind
+	// the parent of the added or removed node for childListMutation,
+	// the changed node otherwise
+	target *html.Node
+
+	// the added or removed node, and its siblings
+	added   *html.Node
+	removed *html.Node
+	prev    *html.Node
+	next    *html.Node
+
+	// the changed attribute, its previous value and whether it existed,
+	// and the attributes of the node before the change
+	attr      string
+	oldValue  string
+	oldExists bool
+	oldAttrs  []html.Attribute
+}
+
+// docState is the state of the transactions and observers of a Document,
+// created by its first transaction or observer. It is only reachable from
+// the Document, so it is released with it.
+type docState struct {
+	doc *Document
+
+	// the active transactions, from the outermost to the innermost, and
+	// the changes they recorded
+	txs []*Tx
+	log []mutation
+
+	// true while undoing changes, which must not be recorded
+	undoing bool
+
+	// the observers of the changes, see Document.Observe
+	observers []*observer
+}
+
+// docState returns the state of the document, creating it if needed.
+func (d *Document) docState() *docState {
+	if d.state == nil {
+		d.state = &docState{doc: d}
+	}
+	return d.state
+}
+
+// inTx returns true if the changes made through the Selections of d are
+// recorded by a transaction. d may be nil.
+func (d *Document) inTx() bool {
+	return d != nil && d.state != nil && len(d.state.txs) > 0 && !d.state.undoing
+}
+
+// record records the change m made through the Selections of d, which may
+// be nil, in its active transactions, notifies its observers and
+// invalidates its index and the numbering of its nodes.
+func record(d *Document, m mutation) {
+	if d == nil {
+		return
+	}
+	if m.kind == childListMutation {
+		atomic.AddUint64(&d.order.version, 1)
+	}
+	if d.index != nil {
+		d.index.invalidate(m)
+	}
+	if d.state == nil {
+		return
+	}
+	if d.inTx() {
+		d.state.log = append(d.state.log, m)
+	}
+	if len(d.state.observers) > 0 && d.contains(m.target) {
+		d.state.notify(m)
+	}
+}
+
+// contains returns true if n is the root node of the document or one of
+// its descendants.
+func (d *Document) contains(n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n == d.rootNode {
+			return true
+		}
+	}
+	return false
+}
+
+// undo undoes the recorded changes after the position pos of the log, in
+// reverse order.
+func (st *docState) undo(pos int) {
+	st.undoing = true
+	defer func() { st.undoing = false }()
+
+	for i := len(st.log) - 1; i >= pos; i-- {
+		m := st.log[i]
+		switch m.kind {
+		case childListMutation:
+			if m.added != nil {
+				if m.added.Parent == m.target {
+					removeChild(st.doc, m.target, m.added)
+				}
+				break
+			}
+			if m.removed.Parent != nil {
+				removeChild(st.doc, m.removed.Parent, m.removed)
+			}
+			next := m.next
+			if next != nil && next.Parent != m.target {
+				next = nil
+			}
+			insertBefore(st.doc, m.target, m.removed, next)
+		case attributesMutation:
+			setAttrs(st.doc, m.target, m.oldAttrs)
+		case characterDataMutation:
+			setNodeData(st.doc, m.target, m.oldValue)
+		}
+		st.log[i] = mutation{}
+	}
+	st.log = st.log[:pos]
+}
+
+// insertBefore inserts n as a child of parent, before ref, or as the last
+// child if ref is nil. If n already has a parent, it is removed from it
+// first. The change is made through the Selections of d, which may be nil,
+// as for the other functions of this file.
+func insertBefore(d *Document, parent, n, ref *html.Node) {
+	if n.Parent != nil {
+		removeChild(d, n.Parent, n)
+	}
+	parent.InsertBefore(n, ref)
+	record(d, mutation{kind: childListMutation, target: parent, added: n, prev: n.PrevSibling, next: ref})
+}
+
+// appendChild appends n as the last child of parent.
+func appendChild(d *Document, parent, n *html.Node) {
+	insertBefore(d, parent, n, nil)
+}
+
+// removeChild removes the child n from parent.
+func removeChild(d *Document, parent, n *html.Node) {
+	prev, next := n.PrevSibling, n.NextSibling
+	parent.RemoveChild(n)
+	record(d, mutation{kind: childListMutation, target: parent, removed: n, prev: prev, next: next})
+}
+
+// setAttr sets the attribute of n to val, adding it if it does not exist.
+func setAttr(d *Document, n *html.Node, attrName, val string) {
+	m := attrMutation(d, n, attrName)
+	if attr := getAttributePtr(attrName, n); attr != nil {
+		attr.Val = val
+	} else {
+		n.Attr = append(n.Attr, html.Attribute{Key: attrName, Val: val})
+	}
+	record(d, m)
+}
+
+// removeAttr removes the attribute of n, if it exists.
+func removeAttr(d *Document, n *html.Node, attrName string) {
+	for i, a := range n.Attr {
+		if a.Key == attrName {
+			m := attrMutation(d, n, attrName)
+			n.Attr[i], n.Attr[len(n.Attr)-1], n.Attr =
+				n.Attr[len(n.Attr)-1], html.Attribute{}, n.Attr[:len(n.Attr)-1]
+			record(d, m)
+			return
+		}
+	}
+}
+
+// setAttrs replaces the attributes of n by a copy of attrs.
+func setAttrs(d *Document, n *html.Node, attrs []html.Attribute) {
+	old := n.Attr
+	n.Attr = append([]html.Attribute(nil), attrs...)
+	// record a change for each attribute that was changed or removed, then
+	// for each attribute that was added
+	for _, a := range old {
+		if v, ok := getAttributeValue(a.Key, n); !ok || v != a.Val {
+			record(d, mutation{kind: attributesMutation, target: n, attr: a.Key,
+				oldValue: a.Val, oldExists: true, oldAttrs: old})
+		}
+	}
+	for _, a := range n.Attr {
+		if !attrsContain(old, a.Key) {
+			record(d, mutation{kind: attributesMutation, target: n, attr: a.Key, oldAttrs: old})
+		}
+	}
+}
+
+// setNodeData sets the data of the text or comment node n.
+func setNodeData(d *Document, n *html.Node, data string) {
+	old := n.Data
+	n.Data = data
+	record(d, mutation{kind: characterDataMutation, target: n, oldValue: old})
+}
+
+// attrMutation returns the record of a change to the attribute of n, to be
+// called before the change. The attributes of n are only copied if they
+// may have to be restored by a transaction.
+func attrMutation(d *Document, n *html.Node, attrName string) mutation {
+	val, exists := getAttributeValue(attrName, n)
+	m := mutation{
+		kind:      attributesMutation,
+		target:    n,
+		attr:      attrName,
+		oldValue:  val,
+		oldExists: exists,
+	}
+	if d.inTx() {
+		m.oldAttrs = append([]html.Attribute(nil), n.Attr...)
+	}
+	return m
+}
+
+func attrsContain(attrs []html.Attribute, key string) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
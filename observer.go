@@ -0,0 +1,114 @@
+package goquery
+
+import (
+	"golang.org/x/net/html"
+)
+
+// MutationType is the type of a MutationRecord.
+type MutationType string
+
+// The types of MutationRecord, as in the DOM.
+const (
+	MutationChildList     MutationType = "childList"
+	MutationAttributes    MutationType = "attributes"
+	MutationCharacterData MutationType = "characterData"
+)
+
+// MutationRecord describes a change made to a document, modelled on the
+// DOM's MutationRecord:
+//
+//   - for MutationChildList, a node was added to or removed from Target,
+//     between PreviousSibling and NextSibling;
+//   - for MutationAttributes, the attribute AttributeName of Target was
+//     added, changed or removed;
+//   - for MutationCharacterData, the text of the text or comment node
+//     Target was changed.
+//
+// Unlike in the DOM, each record holds a single added or removed node, and
+// moving a node is reported as its removal followed by its addition.
+type MutationRecord struct {
+	Type   MutationType
+	Target *html.Node
+
+	// AddedNodes and RemovedNodes hold the node added or removed, for
+	// MutationChildList.
+	AddedNodes   []*html.Node
+	RemovedNodes []*html.Node
+
+	// PreviousSibling and NextSibling are the siblings of the added or
+	// removed node, for MutationChildList.
+	PreviousSibling *html.Node
+	NextSibling     *html.Node
+
+	// AttributeName is the name of the changed attribute, for
+	// MutationAttributes.
+	AttributeName string
+
+	// OldValue is the value of the attribute before the change, for
+	// MutationAttributes, or the previous text of Target, for
+	// MutationCharacterData. HasOldValue is false if the attribute did not
+	// exist before the change.
+	OldValue    string
+	HasOldValue bool
+}
+
+// observer is a function registered by Document.Observe.
+type observer struct {
+	f func(MutationRecord)
+}
+
+// Observe registers f to be called with a MutationRecord for each change
+// made to the document through its Selections (manipulation, attribute,
+// class, property, style, data and form methods, transaction rollbacks,
+// etc.), as it is made. The changes made to the nodes while they are not
+// in the document, those made directly to the html.Node values and those
+// made through another Document created on the same nodes are not
+// reported.
+//
+// f is called synchronously, after the change is made. It may modify the
+// document, which is reported to the observers too. Observe returns a
+// function that unregisters f. The observers are only referenced by the
+// Document, so they do not outlive it if disconnect is not called.
+func (d *Document) Observe(f func(MutationRecord)) (disconnect func()) {
+	st := d.docState()
+	o := &observer{f: f}
+	st.observers = append(st.observers, o)
+
+	return func() {
+		for i, oo := range st.observers {
+			if oo == o {
+				// copy the slice, it may be iterated by notify
+				obs := make([]*observer, 0, len(st.observers)-1)
+				obs = append(obs, st.observers[:i]...)
+				st.observers = append(obs, st.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify calls the observers of the document with the record of m.
+func (st *docState) notify(m mutation) {
+	rec := MutationRecord{Target: m.target}
+	switch m.kind {
+	case childListMutation:
+		rec.Type = MutationChildList
+		if m.added != nil {
+			rec.AddedNodes = []*html.Node{m.added}
+		} else {
+			rec.RemovedNodes = []*html.Node{m.removed}
+		}
+		rec.PreviousSibling, rec.NextSibling = m.prev, m.next
+	case attributesMutation:
+		rec.Type = MutationAttributes
+		rec.AttributeName = m.attr
+		rec.OldValue, rec.HasOldValue = m.oldValue, m.oldExists
+	case characterDataMutation:
+		rec.Type = MutationCharacterData
+		rec.OldValue, rec.HasOldValue = m.oldValue, true
+	}
+
+	for _, o := range st.observers {
+		o.f(rec)
+	}
+}
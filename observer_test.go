@@ -0,0 +1,93 @@
+package goquery
+
+import (
+	"testing"
+)
+
+func TestObserve(t *testing.T) {
+	doc := loadString(t, `<html><body><div id="a" class="x"><p>one</p></div><div id="b"></div></body></html>`)
+
+	var recs []MutationRecord
+	disconnect := doc.Observe(func(r MutationRecord) {
+		recs = append(recs, r)
+	})
+
+	doc.Find("#a").AddClass("y")
+	doc.Find("#a").RemoveAttr("id")
+	doc.Find("#b").SetAttr("hidden", "")
+	doc.Find("p").Contents().SetProp("textContent", "two")
+	doc.Find("p").AppendHtml("<b>!</b>")
+	doc.Find("#b").AppendSelection(doc.Find("p"))
+
+	want := []struct {
+		typ      MutationType
+		target   string
+		attr     string
+		old      string
+		hasOld   bool
+		added    int
+		removed  int
+		prevNode string
+	}{
+		{MutationAttributes, "div", "class", "x", true, 0, 0, ""},
+		{MutationAttributes, "div", "id", "a", true, 0, 0, ""},
+		{MutationAttributes, "div", "hidden", "", false, 0, 0, ""},
+		{MutationCharacterData, "one", "", "one", true, 0, 0, ""},
+		{MutationChildList, "p", "", "", false, 1, 0, "two"},
+		{MutationChildList, "div", "", "", false, 0, 1, ""},
+		{MutationChildList, "div", "", "", false, 1, 0, ""},
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("want %d records, got %d: %+v", len(want), len(recs), recs)
+	}
+	for i, w := range want {
+		r := recs[i]
+		if r.Type != w.typ || r.AttributeName != w.attr || r.OldValue != w.old || r.HasOldValue != w.hasOld ||
+			len(r.AddedNodes) != w.added || len(r.RemovedNodes) != w.removed {
+			t.Errorf("%d: unexpected record %+v", i, r)
+		}
+		// the text node's data has changed since the record
+		if w.typ != MutationCharacterData && r.Target.Data != w.target {
+			t.Errorf("%d: want target %s, got %s", i, w.target, r.Target.Data)
+		}
+		if w.prevNode != "" && (r.PreviousSibling == nil || r.PreviousSibling.Data != w.prevNode) {
+			t.Errorf("%d: want previous sibling %s, got %v", i, w.prevNode, r.PreviousSibling)
+		}
+	}
+
+	disconnect()
+	recs = nil
+	doc.Find("#b").SetAttr("id", "c")
+	if len(recs) != 0 {
+		t.Errorf("expected no record after disconnect, got %d", len(recs))
+	}
+	if n := len(doc.state.observers); n != 0 {
+		t.Errorf("expected no observer, got %d", n)
+	}
+}
+
+func TestObserveRollback(t *testing.T) {
+	doc := loadString(t, `<html><body><div id="a"></div></body></html>`)
+
+	tx := doc.Begin()
+	doc.Find("#a").SetAttr("id", "b").AppendHtml("<p></p>")
+
+	var recs []MutationRecord
+	disconnect := doc.Observe(func(r MutationRecord) {
+		recs = append(recs, r)
+	})
+	defer disconnect()
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("want 2 records, got %d: %+v", len(recs), recs)
+	}
+	if recs[0].Type != MutationChildList || len(recs[0].RemovedNodes) != 1 {
+		t.Errorf("expected the removal of the added node, got %+v", recs[0])
+	}
+	if recs[1].Type != MutationAttributes || recs[1].AttributeName != "id" || recs[1].OldValue != "b" {
+		t.Errorf("expected the restoration of the id, got %+v", recs[1])
+	}
+}
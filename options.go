@@ -0,0 +1,216 @@
+package goquery
+
+import (
+	"errors"
+	"io"
+	"net/url"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Errors returned by NewDocumentFromReaderWithOptions when the document
+// exceeds one of the limits set by the WithMaxBytes, WithMaxNodes and
+// WithMaxDepth options.
+var (
+	ErrMaxBytesExceeded = errors.New("goquery: document exceeds maximum number of bytes")
+	ErrMaxNodesExceeded = errors.New("goquery: document exceeds maximum number of nodes")
+	ErrMaxDepthExceeded = errors.New("goquery: document exceeds maximum depth")
+)
+
+// Option configures the creation of a Document by
+// NewDocumentFromReaderWithOptions.
+type Option func(*options)
+
+// options holds the configuration set by the Options.
+type options struct {
+	url       *url.URL
+	scripting bool
+	maxBytes  int64
+	maxNodes  int
+	maxDepth  int
+
+	charset     bool
+	contentType string
+
+	fragment bool
+	context  *html.Node
+}
+
+// WithURL sets the Document's Url field, which is the URL the document was
+// loaded from.
+func WithURL(u *url.URL) Option {
+	return func(o *options) {
+		o.url = u
+	}
+}
+
+// WithScripting sets the scripting flag of the HTML parser (see
+// html.ParseOptionEnableScripting). When it is enabled, which is the
+// default, the content of <noscript> elements is parsed as raw text,
+// otherwise it is parsed as HTML.
+func WithScripting(enable bool) Option {
+	return func(o *options) {
+		o.scripting = enable
+	}
+}
+
+// WithMaxBytes limits the number of bytes read from the reader. If the
+// reader provides more than n bytes, ErrMaxBytesExceeded is returned. A
+// value of 0 or less means no limit.
+func WithMaxBytes(n int64) Option {
+	return func(o *options) {
+		o.maxBytes = n
+	}
+}
+
+// WithMaxNodes limits the number of nodes of the parsed document, including
+// text and comment nodes. If the document has more than n nodes,
+// ErrMaxNodesExceeded is returned. The check is made once the document is
+// parsed, use WithMaxBytes to bound the work done by the parser. A value of
+// 0 or less means no limit.
+func WithMaxNodes(n int) Option {
+	return func(o *options) {
+		o.maxNodes = n
+	}
+}
+
+// WithMaxDepth limits the depth of the parsed document, the document node
+// itself being at depth 0, its <html> child at depth 1, and so on. If a
+// node is nested deeper than n, ErrMaxDepthExceeded is returned. The check
+// is made once the document is parsed. A value of 0 or less means no limit.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithCharset enables the detection of the document's character encoding
+// and its conversion to UTF-8, as done by NewDocumentFromReaderWithCharset.
+// The contentType is typically the value of the Content-Type header of the
+// HTTP response, it may be empty.
+func WithCharset(contentType string) Option {
+	return func(o *options) {
+		o.charset = true
+		o.contentType = contentType
+	}
+}
+
+// WithFragmentContext parses the content of the reader as an HTML fragment
+// in the context of the given element (see html.ParseFragment), instead of
+// as a complete document. The parsed nodes are the children of the
+// Document's root node. If context is nil, the fragment is parsed as the
+// content of a <body> element.
+func WithFragmentContext(context *html.Node) Option {
+	return func(o *options) {
+		o.fragment = true
+		o.context = context
+	}
+}
+
+// newOptions returns the options configured by opts.
+func newOptions(opts []Option) *options {
+	o := &options{scripting: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// parse parses the content of r according to the options and returns the
+// root node of the document.
+func (o *options) parse(r io.Reader) (*html.Node, string, error) {
+	if o.maxBytes > 0 {
+		r = &maxBytesReader{r: r, n: o.maxBytes}
+	}
+
+	var enc string
+	if o.charset {
+		var err error
+		if r, enc, err = newCharsetReader(r, o.contentType); err != nil {
+			return nil, "", err
+		}
+	}
+
+	popts := []html.ParseOption{html.ParseOptionEnableScripting(o.scripting)}
+	var root *html.Node
+	if o.fragment {
+		context := o.context
+		if context == nil {
+			context = &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+		}
+		nodes, err := html.ParseFragmentWithOptions(r, context, popts...)
+		if err != nil {
+			return nil, "", err
+		}
+		root = &html.Node{Type: html.DocumentNode}
+		for _, n := range nodes {
+			root.AppendChild(n)
+		}
+	} else {
+		var err error
+		if root, err = html.ParseWithOptions(r, popts...); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := o.checkLimits([]*html.Node{root}, 0); err != nil {
+		return nil, "", err
+	}
+	return root, enc, nil
+}
+
+// checkLimits walks the trees under nodes, which are at the given depth,
+// to check the maximum number of nodes and depth.
+func (o *options) checkLimits(nodes []*html.Node, depth int) error {
+	if o.maxNodes <= 0 && o.maxDepth <= 0 {
+		return nil
+	}
+
+	var count int
+	var f func(*html.Node, int) error
+	f = func(n *html.Node, depth int) error {
+		count++
+		if o.maxNodes > 0 && count > o.maxNodes {
+			return ErrMaxNodesExceeded
+		}
+		if o.maxDepth > 0 && depth > o.maxDepth {
+			return ErrMaxDepthExceeded
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if err := f(c, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, n := range nodes {
+		if err := f(n, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxBytesReader is an io.Reader that fails with ErrMaxBytesExceeded if
+// the underlying reader provides more than n bytes.
+type maxBytesReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.n < 0 {
+		return 0, ErrMaxBytesExceeded
+	}
+	// read one byte more than the limit to detect if it is exceeded
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+	n, err := r.r.Read(p)
+	r.n -= int64(n)
+	if r.n < 0 {
+		return n, ErrMaxBytesExceeded
+	}
+	return n, err
+}
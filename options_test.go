@@ -0,0 +1,131 @@
+package goquery
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func TestNewDocumentFromReaderWithOptionsDefault(t *testing.T) {
+	d, err := NewDocumentFromReaderWithOptions(strings.NewReader(`<html><body><h1>Hi</h1></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, d.Find("h1").Nodes, 1)
+	if d.Url != nil {
+		t.Errorf("expected nil Url, got %s", d.Url)
+	}
+	if d.Encoding != "" {
+		t.Errorf("expected no encoding, got %q", d.Encoding)
+	}
+}
+
+func TestWithURL(t *testing.T) {
+	u, _ := url.Parse("https://example.com/a/b")
+	d, err := NewDocumentFromReaderWithOptions(strings.NewReader(`<p>a</p>`), WithURL(u))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Url != u {
+		t.Errorf("expected Url %s, got %s", u, d.Url)
+	}
+}
+
+func TestWithScripting(t *testing.T) {
+	src := `<html><head><noscript><link rel="stylesheet" href="a.css"></noscript></head></html>`
+
+	d, err := NewDocumentFromReaderWithOptions(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, d.Find("noscript link").Nodes, 0)
+
+	d, err = NewDocumentFromReaderWithOptions(strings.NewReader(src), WithScripting(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, d.Find("noscript link").Nodes, 1)
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	src := `<html><body><h1>Hi</h1></body></html>`
+
+	cases := []struct {
+		max int64
+		err error
+	}{
+		{0, nil},
+		{int64(len(src)), nil},
+		{int64(len(src)) + 1, nil},
+		{int64(len(src)) - 1, ErrMaxBytesExceeded},
+		{1, ErrMaxBytesExceeded},
+	}
+	for i, c := range cases {
+		_, err := NewDocumentFromReaderWithOptions(strings.NewReader(src), WithMaxBytes(c.max))
+		if err != c.err {
+			t.Errorf("[%d] - expected error %v, got %v", i, c.err, err)
+		}
+		_, err = NewDocumentFromReaderWithOptions(strings.NewReader(src), WithMaxBytes(c.max), WithCharset(""))
+		if err != c.err {
+			t.Errorf("[%d] - with charset: expected error %v, got %v", i, c.err, err)
+		}
+	}
+}
+
+func TestWithMaxNodes(t *testing.T) {
+	// document, html, head, body, h1, text
+	src := `<html><body><h1>Hi</h1></body></html>`
+
+	if _, err := NewDocumentFromReaderWithOptions(strings.NewReader(src), WithMaxNodes(6)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := NewDocumentFromReaderWithOptions(strings.NewReader(src), WithMaxNodes(5)); err != ErrMaxNodesExceeded {
+		t.Errorf("expected ErrMaxNodesExceeded, got %v", err)
+	}
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	// document (0), html (1), body (2), h1 (3), text (4)
+	src := `<html><body><h1>Hi</h1></body></html>`
+
+	if _, err := NewDocumentFromReaderWithOptions(strings.NewReader(src), WithMaxDepth(4)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, err := NewDocumentFromReaderWithOptions(strings.NewReader(src), WithMaxDepth(3)); err != ErrMaxDepthExceeded {
+		t.Errorf("expected ErrMaxDepthExceeded, got %v", err)
+	}
+}
+
+func TestWithCharset(t *testing.T) {
+	d, err := NewDocumentFromReaderWithOptions(strings.NewReader("<p>Caf\xe9</p>"), WithCharset("text/html; charset=latin1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Encoding != "windows-1252" {
+		t.Errorf("expected encoding windows-1252, got %q", d.Encoding)
+	}
+	if got := d.Find("p").Text(); got != "Café" {
+		t.Errorf("expected Café, got %q", got)
+	}
+}
+
+func TestWithFragmentContext(t *testing.T) {
+	d, err := NewDocumentFromReaderWithOptions(strings.NewReader(`<p>a</p><p>b</p>`), WithFragmentContext(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, d.Children().Nodes, 2)
+	assertLength(t, d.Find("html, body").Nodes, 0)
+
+	table := &html.Node{Type: html.ElementNode, Data: "table", DataAtom: atom.Table}
+	d, err = NewDocumentFromReaderWithOptions(strings.NewReader(`<tr><td>a</td></tr>`), WithFragmentContext(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, d.Children().Nodes, 1)
+	assertSelectionIs(t, d.Children(), "tbody")
+	assertLength(t, d.Find("td").Nodes, 1)
+}
@@ -0,0 +1,259 @@
+package goquery
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/html"
+)
+
+// DocumentPosition is a bitmask describing the position of a node relative
+// to another one, as returned by Document.CompareDocumentPosition.
+type DocumentPosition uint16
+
+// The bits of a DocumentPosition, with the same values as in the DOM.
+const (
+	DocumentPositionDisconnected DocumentPosition = 1 << iota
+	DocumentPositionPreceding
+	DocumentPositionFollowing
+	DocumentPositionContains
+	DocumentPositionContainedBy
+	DocumentPositionImplementationSpecific
+)
+
+// The number of nodes up to which a Selection whose document has changed
+// since its nodes were numbered is sorted by walking their trees, rather
+// than by numbering the nodes of the document again.
+const maxWalkSortNodes = 32
+
+// orderCache holds the pre-order numbering of the nodes of a document.
+type orderCache struct {
+	// incremented by each insertion or removal of a node made through the
+	// Selections of the document, accessed atomically
+	version uint64
+
+	mu    sync.Mutex
+	nodes *nodeOrder
+}
+
+// nodeOrder is the pre-order numbering of the nodes of a document.
+type nodeOrder struct {
+	// the version of the document when the numbering was made
+	version uint64
+	pos     map[*html.Node]nodePos
+}
+
+// nodePos is the position of a node in the pre-order numbering of its
+// document, and the position of its last descendant (or its own, if it has
+// none).
+type nodePos struct {
+	pre, last int
+}
+
+// CompareDocumentPosition returns the position of b relative to a, as
+// a.compareDocumentPosition(b) does in the DOM:
+//
+//   - 0 if a and b are the same node;
+//   - DocumentPositionContains|DocumentPositionPreceding if b is an
+//     ancestor of a;
+//   - DocumentPositionContainedBy|DocumentPositionFollowing if b is a
+//     descendant of a;
+//   - DocumentPositionPreceding or DocumentPositionFollowing if b comes
+//     before or after a in document order;
+//   - DocumentPositionDisconnected|DocumentPositionImplementationSpecific|
+//     DocumentPositionFollowing if a and b are not in the same tree. Unlike
+//     in the DOM, the order of the nodes of different trees is not defined,
+//     so the same value is returned whatever the order of a and b.
+//
+// The positions of the nodes of the document are numbered on first use and
+// cached, so that comparing them takes constant time. The numbering is made
+// again after a change to the document through its Selections; the changes
+// made directly to the html.Node values, or through another Document
+// created on the same nodes, are not detected. The nodes that are not in
+// the document are compared by walking their trees.
+func (d *Document) CompareDocumentPosition(a, b *html.Node) DocumentPosition {
+	if a == b {
+		return 0
+	}
+	pos := d.nodeOrder().pos
+	pa, okA := pos[a]
+	pb, okB := pos[b]
+	if !okA || !okB {
+		return compareNodePosition(a, b)
+	}
+
+	switch {
+	case pb.pre < pa.pre && pa.pre <= pb.last:
+		return DocumentPositionContains | DocumentPositionPreceding
+	case pa.pre < pb.pre && pb.pre <= pa.last:
+		return DocumentPositionContainedBy | DocumentPositionFollowing
+	case pb.pre < pa.pre:
+		return DocumentPositionPreceding
+	default:
+		return DocumentPositionFollowing
+	}
+}
+
+// nodeOrder returns the numbering of the nodes of the document, making it
+// if there is none or if the document has changed since it was made.
+func (d *Document) nodeOrder() *nodeOrder {
+	c := d.order
+	version := atomic.LoadUint64(&c.version)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nodes == nil || c.nodes.version != version {
+		c.nodes = &nodeOrder{version: version, pos: make(map[*html.Node]nodePos)}
+		numberNodes(d.rootNode, 0, c.nodes.pos)
+	}
+	return c.nodes
+}
+
+// numbered returns true if the numbering of the nodes of the document is
+// up to date.
+func (c *orderCache) numbered() bool {
+	version := atomic.LoadUint64(&c.version)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nodes != nil && c.nodes.version == version
+}
+
+// numberNodes numbers n and its descendants in pre-order, from i, and
+// returns the next number.
+func numberNodes(n *html.Node, i int, pos map[*html.Node]nodePos) int {
+	pre := i
+	i++
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		i = numberNodes(c, i, pos)
+	}
+	pos[n] = nodePos{pre: pre, last: i - 1}
+	return i
+}
+
+// compareNodePosition is the implementation of CompareDocumentPosition for
+// the nodes that are not numbered, which walks their ancestors and
+// siblings.
+func compareNodePosition(a, b *html.Node) DocumentPosition {
+	if a == b {
+		return 0
+	}
+	ancA, ancB := ancestorsOf(a), ancestorsOf(b)
+	rootA, rootB := ancA[len(ancA)-1], ancB[len(ancB)-1]
+	if rootA != rootB {
+		return DocumentPositionDisconnected | DocumentPositionImplementationSpecific |
+			DocumentPositionFollowing
+	}
+
+	// walk down from the root to the first ancestors that differ
+	i, j := len(ancA)-1, len(ancB)-1
+	for i >= 0 && j >= 0 && ancA[i] == ancB[j] {
+		i--
+		j--
+	}
+	switch {
+	case i < 0:
+		return DocumentPositionContainedBy | DocumentPositionFollowing
+	case j < 0:
+		return DocumentPositionContains | DocumentPositionPreceding
+	}
+	for n := ancA[i].NextSibling; n != nil; n = n.NextSibling {
+		if n == ancB[j] {
+			return DocumentPositionFollowing
+		}
+	}
+	return DocumentPositionPreceding
+}
+
+// ancestorsOf returns n and its ancestors, up to the root of its tree.
+func ancestorsOf(n *html.Node) []*html.Node {
+	var result []*html.Node
+	for ; n != nil; n = n.Parent {
+		result = append(result, n)
+	}
+	return result
+}
+
+// SortDocumentOrder returns a new Selection object with the nodes of the
+// current Selection sorted in document order. The nodes that are not in
+// the same tree are grouped by tree, in the order of the first node of each
+// tree in the Selection.
+func (s *Selection) SortDocumentOrder() *Selection {
+	return pushStack(s, sortedNodes(s.document, s.Nodes))
+}
+
+// Uniq returns a new Selection object with the nodes of the current
+// Selection sorted in document order, without duplicates.
+func (s *Selection) Uniq() *Selection {
+	return pushStack(s, sortedNodes(s.document, appendWithoutDuplicates(nil, s.Nodes, nil)))
+}
+
+// sortedNodes returns a copy of nodes sorted in document order, comparing
+// them with the numbering of the nodes of d if they are all in it. d may be
+// nil. The numbering is not made again for a few nodes after a change, they
+// are compared by walking their trees instead.
+func sortedNodes(d *Document, nodes []*html.Node) []*html.Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	result := make([]*html.Node, len(nodes))
+	copy(result, nodes)
+
+	if d != nil && (len(result) > maxWalkSortNodes || d.order.numbered()) {
+		pos := d.nodeOrder().pos
+		numbered := true
+		for _, n := range result {
+			if _, ok := pos[n]; !ok {
+				numbered = false
+				break
+			}
+		}
+		if numbered {
+			sort.SliceStable(result, func(i, j int) bool {
+				return pos[result[i]].pre < pos[result[j]].pre
+			})
+			return result
+		}
+	}
+
+	// number the trees in the order of their first node
+	trees := make(map[*html.Node]int)
+	keys := make([]int, len(result))
+	for i, n := range result {
+		root := n
+		for root.Parent != nil {
+			root = root.Parent
+		}
+		k, ok := trees[root]
+		if !ok {
+			k = len(trees)
+			trees[root] = k
+		}
+		keys[i] = k
+	}
+	sort.Stable(treeOrder{nodes: result, trees: keys})
+	return result
+}
+
+// treeOrder sorts nodes by tree, then in document order, by walking their
+// trees.
+type treeOrder struct {
+	nodes []*html.Node
+	// the number of the tree of each node
+	trees []int
+}
+
+func (o treeOrder) Len() int { return len(o.nodes) }
+
+func (o treeOrder) Less(i, j int) bool {
+	if o.trees[i] != o.trees[j] {
+		return o.trees[i] < o.trees[j]
+	}
+	return compareNodePosition(o.nodes[i], o.nodes[j])&DocumentPositionFollowing != 0
+}
+
+func (o treeOrder) Swap(i, j int) {
+	o.nodes[i], o.nodes[j] = o.nodes[j], o.nodes[i]
+	o.trees[i], o.trees[j] = o.trees[j], o.trees[i]
+}
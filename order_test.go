@@ -0,0 +1,117 @@
+package goquery
+
+import (
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestCompareDocumentPosition(t *testing.T) {
+	doc := Doc2Clone()
+	nodes := doc.Find("*").AddNodes(doc.rootNode).Nodes
+	for _, a := range nodes {
+		for _, b := range nodes {
+			if got, want := doc.CompareDocumentPosition(a, b), compareNodePosition(a, b); got != want {
+				t.Fatalf("%s/%s: want %b, got %b", a.Data, b.Data, want, got)
+			}
+		}
+	}
+
+	main, foot := doc.Find("#main").Get(0), doc.Find("#foot").Get(0)
+	body := doc.Find("body").Get(0)
+	cases := []struct {
+		a, b *html.Node
+		want DocumentPosition
+	}{
+		{main, main, 0},
+		{main, foot, DocumentPositionFollowing},
+		{foot, main, DocumentPositionPreceding},
+		{main, body, DocumentPositionContains | DocumentPositionPreceding},
+		{body, main, DocumentPositionContainedBy | DocumentPositionFollowing},
+	}
+	for i, c := range cases {
+		if got := doc.CompareDocumentPosition(c.a, c.b); got != c.want {
+			t.Errorf("%d: want %b, got %b", i, c.want, got)
+		}
+	}
+
+	other := Doc2Clone().Find("#main").Get(0)
+	ab, ba := doc.CompareDocumentPosition(main, other), doc.CompareDocumentPosition(other, main)
+	disconnected := DocumentPositionDisconnected | DocumentPositionImplementationSpecific | DocumentPositionFollowing
+	if ab != disconnected || ba != disconnected {
+		t.Errorf("unexpected positions of disconnected nodes: %b and %b", ab, ba)
+	}
+
+	// the numbering is made again after a change
+	doc.Find("#foot").AfterSelection(doc.Find("#main"))
+	if got := doc.CompareDocumentPosition(main, foot); got != DocumentPositionPreceding {
+		t.Errorf("want %b after the move, got %b", DocumentPositionPreceding, got)
+	}
+}
+
+func TestSortDocumentOrder(t *testing.T) {
+	sel := Doc().Find(".pvk-content")
+	rev := Doc().FindNodes(sel.Nodes[2], sel.Nodes[0], sel.Nodes[1])
+	sorted := rev.SortDocumentOrder()
+	assertIndexedNodes(t, "sort", sorted.Nodes, sel.Nodes)
+	assertEqual(t, sorted.End(), rev)
+
+	uniq := Doc().FindNodes(sel.Nodes[1], sel.Nodes[0], sel.Nodes[1]).Uniq()
+	assertIndexedNodes(t, "uniq", uniq.Nodes, sel.Nodes[:2])
+
+	// without the numbering of a document
+	detached := &Selection{Nodes: rev.Nodes}
+	assertIndexedNodes(t, "detached", detached.SortDocumentOrder().Nodes, sel.Nodes)
+
+	// the nodes of other trees are grouped by tree
+	other := CloneDocument(Doc()).Find(".pvk-content").Nodes
+	mixed := &Selection{Nodes: []*html.Node{other[1], sel.Nodes[1], other[0], sel.Nodes[0]}}
+	assertIndexedNodes(t, "mixed", mixed.SortDocumentOrder().Nodes,
+		[]*html.Node{other[0], other[1], sel.Nodes[0], sel.Nodes[1]})
+}
+
+func TestSortDocumentOrderAfterChange(t *testing.T) {
+	doc := CloneDocument(Doc())
+	sel := doc.Find(".pvk-content")
+	a, b := sel.Get(0), sel.Get(1)
+	assertIndexedNodes(t, "before", doc.FindNodes(b, a).SortDocumentOrder().Nodes, []*html.Node{a, b})
+	if doc.CompareDocumentPosition(a, b) != DocumentPositionFollowing || !doc.order.numbered() {
+		t.Fatal("expected the document to be numbered")
+	}
+
+	// a few nodes are sorted without numbering the document again
+	doc.FindNodes(b).AfterNodes(a)
+	assertIndexedNodes(t, "after", doc.FindNodes(a, b).SortDocumentOrder().Nodes, []*html.Node{b, a})
+	if doc.order.numbered() {
+		t.Error("expected the document not to be numbered again")
+	}
+
+	all := doc.Find("*").Nodes
+	if len(all) <= maxWalkSortNodes {
+		t.Fatalf("want more than %d nodes, got %d", maxWalkSortNodes, len(all))
+	}
+	rev := make([]*html.Node, len(all))
+	for i, n := range all {
+		rev[len(all)-1-i] = n
+	}
+	assertIndexedNodes(t, "all", doc.FindNodes(rev...).SortDocumentOrder().Nodes, all)
+	if !doc.order.numbered() {
+		t.Error("expected the document to be numbered again")
+	}
+}
+
+func TestUnionDocumentOrder(t *testing.T) {
+	sel := Doc().Find("a")
+	sel2 := Doc().Find("div.row-fluid")
+	union := sel.Union(sel2)
+	assertLength(t, union.Nodes, 19)
+	assertIndexedNodes(t, "union", union.Nodes, Doc().Find("a, div.row-fluid").Nodes)
+	assertEqual(t, union.End(), sel)
+}
+
+func TestIntersectionDocumentOrder(t *testing.T) {
+	sel := Doc().Find(".pvk-gutter")
+	rev := Doc().FindNodes(sel.Nodes[5], sel.Nodes[3], sel.Nodes[1])
+	inter := rev.Intersection(sel)
+	assertIndexedNodes(t, "intersection", inter.Nodes, []*html.Node{sel.Nodes[1], sel.Nodes[3], sel.Nodes[5]})
+}
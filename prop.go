@@ -0,0 +1,530 @@
+package goquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Prop gets the value of the named DOM property for the first element in
+// the Selection, like jQuery's prop(). Unlike Attr, which returns the raw
+// attribute value, Prop returns the value the property has in a browser's
+// DOM, typed accordingly:
+//
+//   - boolean properties such as checked, selected, disabled, readOnly,
+//     multiple, required or hidden are returned as a bool, true if the
+//     corresponding attribute is present, whatever its value;
+//   - the selected property of an <option> is also true if the option is
+//     selected by default (see Val);
+//   - URL properties such as href, src, action or formAction are returned
+//     as a string, resolved against the document's base URL (see
+//     Document.BaseURL);
+//   - value is the current value of the control, as returned by Val;
+//   - numeric properties such as selectedIndex, tabIndex, maxLength,
+//     colSpan, rowSpan and nodeType are returned as an int, with the
+//     default value of the DOM when the attribute is missing or invalid;
+//   - tagName and nodeName are returned in upper case for HTML elements;
+//   - textContent is the combined text of the node (see Text);
+//   - the other properties are returned as the string value of the
+//     reflected attribute (e.g. className for class, htmlFor for for), or
+//     an empty string if it is missing.
+//
+// The property names are those of the DOM (e.g. readOnly, className), the
+// names of the reflected attributes are accepted too (e.g. readonly, class).
+// Since there is no browser state, the properties that hold the current
+// state of a control in a browser, such as checked, selected and value,
+// are reflected on the same attributes as their default* counterparts.
+//
+// The exists return value is false if the Selection is empty, or if the
+// property is not supported or does not apply to the first element (e.g.
+// checked on a <div>).
+func (s *Selection) Prop(name string) (val interface{}, exists bool) {
+	if len(s.Nodes) == 0 {
+		return nil, false
+	}
+	p := lookupProp(name)
+	if p == nil || !p.appliesTo(s.Nodes[0]) {
+		return nil, false
+	}
+	return p.get(s.document, s.Nodes[0]), true
+}
+
+// SetProp sets the named DOM property (see Prop) on each element in the set
+// of matched elements, reflecting it on the corresponding attribute:
+// boolean properties add or remove the attribute, numeric properties set it
+// to the decimal value, and the others set it to the string value. The
+// value is converted to the type of the property following the JavaScript
+// rules (e.g. a non-empty string is true, a string is parsed as a number),
+// and the elements for which it cannot be converted are left unchanged.
+//
+// Setting checked on a radio button unchecks the other radio buttons of its
+// group, setting selected on an option or selectedIndex on a select
+// unselects the other options if the select is not multiple, and setting
+// textContent replaces the children of the node by a single text node.
+// Read-only properties such as tagName, nodeName and nodeType, unsupported
+// properties and the elements the property does not apply to are ignored.
+// It returns the Selection.
+func (s *Selection) SetProp(name string, val interface{}) *Selection {
+	p := lookupProp(name)
+	if p == nil || p.set == nil {
+		return s
+	}
+	for _, n := range s.Nodes {
+		if p.appliesTo(n) {
+			p.set(s.document, n, val)
+		}
+	}
+	return s
+}
+
+// domProp describes how a DOM property is read from and written to the
+// attributes of an element.
+type domProp struct {
+	// the elements the property applies to, all elements if nil
+	elems []atom.Atom
+	// true if the property applies to all node types, not only elements
+	anyNode bool
+
+	get func(doc *Document, n *html.Node) interface{}
+	// nil if the property is read-only
+	set func(doc *Document, n *html.Node, val interface{})
+}
+
+func (p *domProp) appliesTo(n *html.Node) bool {
+	if p.anyNode {
+		return true
+	}
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if p.elems == nil {
+		return true
+	}
+	// the elements of other namespaces (svg, math) don't have the HTML
+	// element-specific properties
+	if n.Namespace != "" {
+		return false
+	}
+	for _, a := range p.elems {
+		if n.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupProp returns the property by its DOM name, or by the name of its
+// reflected attribute. It returns nil if the property is not supported.
+func lookupProp(name string) *domProp {
+	if p, ok := domProps[name]; ok {
+		return p
+	}
+	if canon, ok := propAttrNames[strings.ToLower(name)]; ok {
+		return domProps[canon]
+	}
+	return nil
+}
+
+// boolProp returns a boolean property reflected on the attribute attr.
+func boolProp(attr string, elems ...atom.Atom) *domProp {
+	return &domProp{
+		elems: elems,
+		get: func(_ *Document, n *html.Node) interface{} {
+			return hasAttr(n, attr)
+		},
+		set: func(doc *Document, n *html.Node, val interface{}) {
+			setBoolAttr(doc, n, attr, propBool(val))
+		},
+	}
+}
+
+// stringProp returns a string property reflected on the attribute attr.
+func stringProp(attr string, elems ...atom.Atom) *domProp {
+	return &domProp{
+		elems: elems,
+		get: func(_ *Document, n *html.Node) interface{} {
+			val, _ := getAttributeValue(attr, n)
+			return val
+		},
+		set: func(doc *Document, n *html.Node, val interface{}) {
+			setAttr(doc, n, attr, propString(val))
+		},
+	}
+}
+
+// urlProp returns a string property reflected on the attribute attr, that
+// holds a URL resolved against the document's base URL.
+func urlProp(attr string, elems ...atom.Atom) *domProp {
+	p := stringProp(attr, elems...)
+	p.get = func(doc *Document, n *html.Node) interface{} {
+		val, ok := getAttributeValue(attr, n)
+		if !ok || doc == nil {
+			return val
+		}
+		if u, e := doc.AbsURL(val); e == nil {
+			return u.String()
+		}
+		return val
+	}
+	return p
+}
+
+// intProp returns a numeric property reflected on the attribute attr, with
+// the value def if the attribute is missing or invalid. If min is true, the
+// value must also be greater than 0.
+func intProp(attr string, def int, min bool, elems ...atom.Atom) *domProp {
+	return &domProp{
+		elems: elems,
+		get: func(_ *Document, n *html.Node) interface{} {
+			val, ok := getAttributeValue(attr, n)
+			if !ok {
+				return def
+			}
+			i, e := strconv.Atoi(strings.TrimSpace(val))
+			if e != nil || (min && i <= 0) {
+				return def
+			}
+			return i
+		},
+		set: func(doc *Document, n *html.Node, val interface{}) {
+			if i, ok := propInt(val); ok {
+				setAttr(doc, n, attr, strconv.Itoa(i))
+			}
+		},
+	}
+}
+
+// domProps is the table of the supported properties, by DOM name.
+var domProps = map[string]*domProp{
+	// boolean properties
+	"allowFullscreen": boolProp("allowfullscreen", atom.Iframe),
+	"async":           boolProp("async", atom.Script),
+	"autofocus":       boolProp("autofocus"),
+	"autoplay":        boolProp("autoplay", atom.Audio, atom.Video),
+	"checked":         {elems: []atom.Atom{atom.Input}, get: getChecked, set: setChecked},
+	"controls":        boolProp("controls", atom.Audio, atom.Video),
+	"default":         boolProp("default", atom.Track),
+	"defaultChecked":  boolProp("checked", atom.Input),
+	"defaultSelected": boolProp("selected", atom.Option),
+	"defer":           boolProp("defer", atom.Script),
+	"disabled":        boolProp("disabled", atom.Button, atom.Fieldset, atom.Input, atom.Link, atom.Optgroup, atom.Option, atom.Select, atom.Style, atom.Textarea),
+	"formNoValidate":  boolProp("formnovalidate", atom.Button, atom.Input),
+	"hidden":          boolProp("hidden"),
+	"inert":           boolProp("inert"),
+	"isMap":           boolProp("ismap", atom.Img),
+	"loop":            boolProp("loop", atom.Audio, atom.Video),
+	"multiple":        boolProp("multiple", atom.Input, atom.Select),
+	"muted":           boolProp("muted", atom.Audio, atom.Video),
+	"noModule":        boolProp("nomodule", atom.Script),
+	"noValidate":      boolProp("novalidate", atom.Form),
+	"open":            boolProp("open", atom.Details, atom.Dialog),
+	"readOnly":        boolProp("readonly", atom.Input, atom.Textarea),
+	"required":        boolProp("required", atom.Input, atom.Select, atom.Textarea),
+	"reversed":        boolProp("reversed", atom.Ol),
+	"selected":        {elems: []atom.Atom{atom.Option}, get: getSelected, set: setSelected},
+
+	// string properties
+	"accessKey":    stringProp("accesskey"),
+	"alt":          stringProp("alt", atom.Area, atom.Img, atom.Input),
+	"className":    stringProp("class"),
+	"defaultValue": stringProp("value", atom.Input),
+	"dir":          stringProp("dir"),
+	"htmlFor":      stringProp("for", atom.Label, atom.Output),
+	"id":           stringProp("id"),
+	"lang":         stringProp("lang"),
+	"name":         stringProp("name", atom.Button, atom.Fieldset, atom.Form, atom.Iframe, atom.Input, atom.Map, atom.Meta, atom.Object, atom.Output, atom.Select, atom.Textarea),
+	"placeholder":  stringProp("placeholder", atom.Input, atom.Textarea),
+	"rel":          stringProp("rel", atom.A, atom.Area, atom.Form, atom.Link),
+	"target":       stringProp("target", atom.A, atom.Area, atom.Base, atom.Form),
+	"title":        stringProp("title"),
+	"type":         {elems: []atom.Atom{atom.Button, atom.Input}, get: getType, set: stringProp("type").set},
+	"value":        {elems: []atom.Atom{atom.Button, atom.Data, atom.Input, atom.Option, atom.Output, atom.Param, atom.Select, atom.Textarea}, get: getValue, set: setValue},
+
+	// URL properties
+	"action":     urlProp("action", atom.Form),
+	"cite":       urlProp("cite", atom.Blockquote, atom.Del, atom.Ins, atom.Q),
+	"formAction": urlProp("formaction", atom.Button, atom.Input),
+	"href":       urlProp("href", atom.A, atom.Area, atom.Base, atom.Link),
+	"poster":     urlProp("poster", atom.Video),
+	"src":        urlProp("src", atom.Audio, atom.Embed, atom.Iframe, atom.Img, atom.Input, atom.Script, atom.Source, atom.Track, atom.Video),
+
+	// numeric properties
+	"colSpan":       intProp("colspan", 1, true, atom.Td, atom.Th),
+	"maxLength":     intProp("maxlength", -1, false, atom.Input, atom.Textarea),
+	"rowSpan":       intProp("rowspan", 1, false, atom.Td, atom.Th),
+	"selectedIndex": {elems: []atom.Atom{atom.Select}, get: getSelectedIndex, set: setSelectedIndex},
+	"tabIndex":      {get: getTabIndex, set: intProp("tabindex", 0, false).set},
+
+	// node properties
+	"localName":   {get: func(_ *Document, n *html.Node) interface{} { return n.Data }},
+	"nodeName":    {anyNode: true, get: getNodeName},
+	"nodeType":    {anyNode: true, get: getNodeType},
+	"tagName":     {get: getTagName},
+	"textContent": {anyNode: true, get: getTextContent, set: setTextContent},
+}
+
+// propAttrNames maps the lowercased names of the reflected attributes, and
+// of the DOM properties, to the DOM names of the properties.
+var propAttrNames = map[string]string{
+	"class":    "className",
+	"for":      "htmlFor",
+	"readonly": "readOnly",
+}
+
+func init() {
+	for name := range domProps {
+		if _, ok := propAttrNames[strings.ToLower(name)]; !ok {
+			propAttrNames[strings.ToLower(name)] = name
+		}
+	}
+}
+
+func getChecked(_ *Document, n *html.Node) interface{} {
+	return hasAttr(n, "checked")
+}
+
+// setChecked checks or unchecks the input n, unchecking the other radio
+// buttons of its group if it is a checked radio button.
+func setChecked(doc *Document, n *html.Node, val interface{}) {
+	checked := propBool(val)
+	setBoolAttr(doc, n, "checked", checked)
+	if checked && inputType(n) == "radio" {
+		uncheckRadioGroup(doc, n)
+	}
+}
+
+func getSelected(_ *Document, n *html.Node) interface{} {
+	if hasAttr(n, "selected") {
+		return true
+	}
+	if sel := optionSelect(n); sel != nil {
+		for _, opt := range selectedOptions(sel) {
+			if opt == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setSelected selects or unselects the option n, unselecting the other
+// options of its select if it is not multiple.
+func setSelected(doc *Document, n *html.Node, val interface{}) {
+	selected := propBool(val)
+	if sel := optionSelect(n); selected && sel != nil && !hasAttr(sel, "multiple") {
+		for _, opt := range selectOptions(sel) {
+			removeAttr(doc, opt, "selected")
+		}
+	}
+	setBoolAttr(doc, n, "selected", selected)
+}
+
+// optionSelect returns the select element of the option n, or nil.
+func optionSelect(n *html.Node) *html.Node {
+	p := n.Parent
+	if p != nil && p.Type == html.ElementNode && p.DataAtom == atom.Optgroup {
+		p = p.Parent
+	}
+	if p != nil && p.Type == html.ElementNode && p.DataAtom == atom.Select {
+		return p
+	}
+	return nil
+}
+
+func getSelectedIndex(_ *Document, n *html.Node) interface{} {
+	selected := selectedOptions(n)
+	if len(selected) == 0 {
+		return -1
+	}
+	for i, opt := range selectOptions(n) {
+		if opt == selected[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+// setSelectedIndex selects the option at the index of the select n, and
+// unselects the others. A negative or out of range index unselects all
+// options.
+func setSelectedIndex(doc *Document, n *html.Node, val interface{}) {
+	idx, ok := propInt(val)
+	if !ok {
+		return
+	}
+	for i, opt := range selectOptions(n) {
+		setBoolAttr(doc, opt, "selected", i == idx)
+	}
+}
+
+// getType returns the type of the input or button n, normalized as the
+// DOM does.
+func getType(_ *Document, n *html.Node) interface{} {
+	if n.DataAtom == atom.Input {
+		return inputType(n)
+	}
+	typ, _ := getAttributeValue("type", n)
+	switch typ = strings.ToLower(strings.TrimSpace(typ)); typ {
+	case "reset", "button":
+		return typ
+	}
+	return "submit"
+}
+
+func getValue(_ *Document, n *html.Node) interface{} {
+	return newSingleSelection(n, nil).Val()
+}
+
+// setValue sets the value of the control n. Unlike SetVal, it sets the
+// value attribute of checkboxes and radio buttons instead of checking them.
+func setValue(doc *Document, n *html.Node, val interface{}) {
+	str := propString(val)
+	if n.DataAtom == atom.Input && isCheckable(n) {
+		setAttr(doc, n, "value", str)
+		return
+	}
+	newSingleSelection(n, doc).SetVal(str)
+}
+
+// getTabIndex returns the tab index of n, which defaults to 0 for the
+// focusable elements and -1 for the others.
+func getTabIndex(_ *Document, n *html.Node) interface{} {
+	if val, ok := getAttributeValue("tabindex", n); ok {
+		if i, e := strconv.Atoi(strings.TrimSpace(val)); e == nil {
+			return i
+		}
+	}
+	if n.Namespace == "" {
+		switch n.DataAtom {
+		case atom.A, atom.Area:
+			if hasAttr(n, "href") {
+				return 0
+			}
+		case atom.Button, atom.Iframe, atom.Input, atom.Select, atom.Summary, atom.Textarea:
+			return 0
+		}
+	}
+	return -1
+}
+
+func getTagName(_ *Document, n *html.Node) interface{} {
+	if n.Namespace == "" {
+		return strings.ToUpper(n.Data)
+	}
+	return n.Data
+}
+
+func getNodeName(doc *Document, n *html.Node) interface{} {
+	switch n.Type {
+	case html.ElementNode:
+		return getTagName(doc, n)
+	case html.TextNode:
+		return "#text"
+	case html.CommentNode:
+		return "#comment"
+	case html.DocumentNode:
+		return "#document"
+	case html.DoctypeNode:
+		return n.Data
+	}
+	return ""
+}
+
+func getNodeType(_ *Document, n *html.Node) interface{} {
+	switch n.Type {
+	case html.ElementNode:
+		return 1
+	case html.TextNode:
+		return 3
+	case html.CommentNode:
+		return 8
+	case html.DocumentNode:
+		return 9
+	case html.DoctypeNode:
+		return 10
+	}
+	return 0
+}
+
+func getTextContent(_ *Document, n *html.Node) interface{} {
+	switch n.Type {
+	case html.TextNode, html.CommentNode:
+		return n.Data
+	}
+	return newSingleSelection(n, nil).Text()
+}
+
+// setTextContent sets the data of the text or comment node n, or replaces
+// the children of n by a text node.
+func setTextContent(doc *Document, n *html.Node, val interface{}) {
+	str := propString(val)
+	switch n.Type {
+	case html.TextNode, html.CommentNode:
+		setNodeData(doc, n, str)
+		return
+	case html.ElementNode, html.DocumentNode:
+	default:
+		return
+	}
+	for c := n.FirstChild; c != nil; c = n.FirstChild {
+		removeChild(doc, n, c)
+	}
+	if str != "" {
+		appendChild(doc, n, &html.Node{Type: html.TextNode, Data: str})
+	}
+}
+
+// propBool converts val to a bool, following the JavaScript rules.
+func propBool(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case int:
+		return v != 0
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	}
+	return true
+}
+
+// propString converts val to a string.
+func propString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	return fmt.Sprint(val)
+}
+
+// propInt converts val to an int, parsing it if it is a string. It returns
+// false if it cannot be converted.
+func propInt(val interface{}) (int, bool) {
+	switch v := val.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		i, e := strconv.Atoi(strings.TrimSpace(v))
+		return i, e == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,142 @@
+package goquery
+
+import (
+	"net/url"
+	"testing"
+)
+
+const propHTML = `<html><head><base href="/base/"></head><body>
+<a id="link" class="x y" href="page?q=1" tabindex="3">Link</a>
+<label for="user">User</label>
+<input id="user" name="user" value="bob" readonly maxlength="x">
+<input type="checkbox" id="cb" checked="false">
+<input type="radio" name="r" id="r1" checked>
+<input type="radio" name="r" id="r2">
+<input type="CheckBox" id="cb2">
+<button id="btn">Go</button>
+<select id="sel">
+	<option id="o1">one</option>
+	<optgroup><option id="o2" value="2">two</option></optgroup>
+</select>
+<table><tr><td id="td" colspan="0">cell</td></tr></table>
+<div id="div"><p>Hello</p> <p>world</p></div>
+<svg><circle id="c"></circle></svg>
+</body></html>`
+
+func TestProp(t *testing.T) {
+	doc := loadString(t, propHTML)
+	doc.Url, _ = url.Parse("http://example.com/dir/doc.html")
+
+	cases := []struct {
+		sel, name string
+		want      interface{}
+	}{
+		{"#link", "href", "http://example.com/base/page?q=1"},
+		{"#link", "className", "x y"},
+		{"#link", "class", "x y"},
+		{"#link", "tabIndex", 3},
+		{"#link", "tagName", "A"},
+		{"#link", "nodeName", "A"},
+		{"#link", "nodeType", 1},
+		{"#link", "textContent", "Link"},
+		{"label", "htmlFor", "user"},
+		{"#user", "readOnly", true},
+		{"#user", "readonly", true},
+		{"#user", "disabled", false},
+		{"#user", "value", "bob"},
+		{"#user", "type", "text"},
+		{"#user", "maxLength", -1},
+		{"#user", "tabIndex", 0},
+		{"#cb", "checked", true},
+		{"#cb", "value", "on"},
+		{"#cb2", "type", "checkbox"},
+		{"#cb2", "checked", false},
+		{"#btn", "type", "submit"},
+		{"#sel", "selectedIndex", 0},
+		{"#sel", "value", "one"},
+		{"#o1", "selected", true},
+		{"#o1", "defaultSelected", false},
+		{"#o2", "selected", false},
+		{"#td", "colSpan", 1},
+		{"#div", "tabIndex", -1},
+		{"#div", "textContent", "Hello world"},
+		{"#c", "tagName", "circle"},
+	}
+	for _, c := range cases {
+		got, ok := doc.Find(c.sel).Prop(c.name)
+		if !ok {
+			t.Errorf("%s %s: expected the property to exist", c.sel, c.name)
+		} else if got != c.want {
+			t.Errorf("%s %s: want %#v, got %#v", c.sel, c.name, c.want, got)
+		}
+	}
+
+	if v, ok := doc.Find("#div p").Contents().Prop("nodeName"); !ok || v != "#text" {
+		t.Errorf("expected #text node name, got %v", v)
+	}
+	for _, name := range []string{"checked", "selectedIndex", "unknown"} {
+		if v, ok := doc.Find("#div").Prop(name); ok {
+			t.Errorf("%s: expected no property on a div, got %v", name, v)
+		}
+	}
+	if _, ok := doc.Find("#c").Prop("hidden"); !ok {
+		t.Error("expected global properties to apply to svg elements")
+	}
+	if _, ok := doc.Find("#missing").Prop("id"); ok {
+		t.Error("expected no property on an empty selection")
+	}
+}
+
+func TestSetProp(t *testing.T) {
+	doc := loadString(t, propHTML)
+
+	doc.Find("#user").SetProp("disabled", true).SetProp("readOnly", "").SetProp("value", "alice")
+	if _, ok := doc.Find("#user").Attr("disabled"); !ok {
+		t.Error("expected disabled attribute")
+	}
+	if _, ok := doc.Find("#user").Attr("readonly"); ok {
+		t.Error("expected no readonly attribute")
+	}
+	if v, _ := doc.Find("#user").Attr("value"); v != "alice" {
+		t.Errorf("expected value alice, got %q", v)
+	}
+
+	doc.Find("#r2").SetProp("checked", true)
+	if _, ok := doc.Find("#r1").Attr("checked"); ok {
+		t.Error("expected r1 to be unchecked")
+	}
+	doc.Find("#cb").SetProp("value", "yes")
+	if v, _ := doc.Find("#cb").Attr("value"); v != "yes" {
+		t.Errorf("expected checkbox value yes, got %q", v)
+	}
+	if v, _ := doc.Find("#cb").Prop("checked"); v != true {
+		t.Error("expected checkbox to remain checked")
+	}
+
+	doc.Find("#sel").SetProp("selectedIndex", "1")
+	if v, _ := doc.Find("#sel").Prop("value"); v != "2" {
+		t.Errorf("expected select value 2, got %v", v)
+	}
+	doc.Find("#o1").SetProp("selected", 1)
+	if _, ok := doc.Find("#o2").Attr("selected"); ok {
+		t.Error("expected o2 to be unselected")
+	}
+
+	doc.Find("#td").SetProp("colSpan", 2).SetProp("colSpan", "bad")
+	if v, _ := doc.Find("#td").Attr("colspan"); v != "2" {
+		t.Errorf("expected colspan 2, got %q", v)
+	}
+
+	doc.Find("#div").SetProp("textContent", "<b>new</b>").SetProp("tagName", "span")
+	if h, _ := doc.Find("#div").Html(); h != "&lt;b&gt;new&lt;/b&gt;" {
+		t.Errorf("unexpected html %q", h)
+	}
+	if NodeName(doc.Find("#div")) != "div" {
+		t.Error("expected tagName to be read-only")
+	}
+
+	doc.Find("#div").SetProp("checked", true)
+	if _, ok := doc.Find("#div").Attr("checked"); ok {
+		t.Error("expected checked to be ignored on a div")
+	}
+}
@@ -1,11 +1,14 @@
 package goquery
 
 import (
+	"bufio"
 	"bytes"
+	"io"
 	"regexp"
 	"strings"
 
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 var rxClassTrim = regexp.MustCompile("[\t\r\n]")
@@ -37,7 +40,7 @@ func (s *Selection) AttrOr(attrName, defaultValue string) string {
 // RemoveAttr removes the named attribute from each element in the set of matched elements.
 func (s *Selection) RemoveAttr(attrName string) *Selection {
 	for _, n := range s.Nodes {
-		removeAttr(n, attrName)
+		removeAttr(s.document, n, attrName)
 	}
 
 	return s
@@ -46,12 +49,7 @@ func (s *Selection) RemoveAttr(attrName string) *Selection {
 // SetAttr sets the given attribute on each element in the set of matched elements.
 func (s *Selection) SetAttr(attrName, val string) *Selection {
 	for _, n := range s.Nodes {
-		attr := getAttributePtr(attrName, n)
-		if attr == nil {
-			n.Attr = append(n.Attr, html.Attribute{Key: attrName, Val: val})
-		} else {
-			attr.Val = val
-		}
+		setAttr(s.document, n, attrName, val)
 	}
 
 	return s
@@ -82,6 +80,64 @@ func (s *Selection) Text() string {
 	return buf.String()
 }
 
+// TextOptions configures the text returned by TextWithOptions. Its zero
+// value follows the innerText algorithm (see InnerText).
+type TextOptions struct {
+	// PreserveWhitespace keeps the whitespace of the text nodes as-is,
+	// instead of collapsing it outside of <pre> elements.
+	PreserveWhitespace bool
+
+	// IncludeHidden includes the text of the elements that are not rendered,
+	// such as <script>, <style> and <template> elements or elements with a
+	// hidden attribute.
+	IncludeHidden bool
+
+	// CellSeparator is written between the cells of a table row. If empty,
+	// a tab is used.
+	CellSeparator string
+}
+
+// InnerText gets the combined text contents of each element in the set of
+// matched elements, including their descendants, as it would be rendered,
+// following the HTML innerText algorithm. Unlike Text:
+//
+//   - block elements are separated by line breaks, and <p> elements by
+//     an empty line;
+//   - <br> elements are rendered as a line break;
+//   - whitespace is collapsed and removed at the start and end of lines,
+//     except in <pre> elements;
+//   - the elements that are not rendered, such as <script>, <style>,
+//     <template> and <head> elements, elements with a hidden attribute
+//     or a display:none inline style and the content of closed <details>
+//     elements except their <summary>, are skipped;
+//   - the cells of a table row are separated by tabs, and the rows by
+//     line breaks.
+//
+// Since there is no CSS engine, the layout is based on the default display
+// of the elements and inline styles are only checked for display:none.
+func (s *Selection) InnerText() string {
+	return s.TextWithOptions(TextOptions{})
+}
+
+// TextWithOptions works like InnerText, with the rendering configured by
+// opts.
+func (s *Selection) TextWithOptions(opts TextOptions) string {
+	if opts.CellSeparator == "" {
+		opts.CellSeparator = "\t"
+	}
+	w := &textWriter{opts: opts}
+	for _, n := range s.Nodes {
+		if !opts.IncludeHidden && n.Type == html.ElementNode && !isRendered(n) {
+			// like innerText, the raw text is returned for elements that are
+			// not rendered
+			w.text(newSingleSelection(n, nil).Text(), true)
+			continue
+		}
+		w.node(n, false)
+	}
+	return w.buf.String()
+}
+
 // Size is an alias for Length.
 func (s *Selection) Size() int {
 	return s.Length()
@@ -112,6 +168,37 @@ func (s *Selection) Html() (ret string, e error) {
 	return
 }
 
+// WriteHtml writes the HTML contents of each element in the set of matched
+// elements to w, unlike Html which only returns those of the first element.
+// It includes text and comment nodes. The output is streamed to w, without
+// building intermediate strings.
+func (s *Selection) WriteHtml(w io.Writer) error {
+	return writeNodes(w, s.Nodes, true)
+}
+
+// WriteOuterHtml writes the outer HTML of each element in the set of
+// matched elements to w, that is, the elements including their tags and
+// attributes. The output is streamed to w, without building intermediate
+// strings.
+func (s *Selection) WriteOuterHtml(w io.Writer) error {
+	return writeNodes(w, s.Nodes, false)
+}
+
+// OuterHtmlAll returns the outer HTML of each element in the set of matched
+// elements, unlike OuterHtml which only returns that of the first element.
+func (s *Selection) OuterHtmlAll() ([]string, error) {
+	result := make([]string, 0, len(s.Nodes))
+	var buf bytes.Buffer
+	for _, n := range s.Nodes {
+		buf.Reset()
+		if e := html.Render(&buf, n); e != nil {
+			return nil, e
+		}
+		result = append(result, buf.String())
+	}
+	return result, nil
+}
+
 // AddClass adds the given class(es) to each element in the set of matched elements.
 // Multiple class names can be specified, separated by a space or via multiple arguments.
 func (s *Selection) AddClass(class ...string) *Selection {
@@ -123,14 +210,14 @@ func (s *Selection) AddClass(class ...string) *Selection {
 
 	tcls := getClassesSlice(classStr)
 	for _, n := range s.Nodes {
-		curClasses, attr := getClassesAndAttr(n, true)
+		curClasses := getClasses(n)
 		for _, newClass := range tcls {
 			if !strings.Contains(curClasses, " "+newClass+" ") {
 				curClasses += newClass + " "
 			}
 		}
 
-		setClasses(n, attr, curClasses)
+		setClasses(s.document, n, curClasses)
 	}
 
 	return s
@@ -141,7 +228,7 @@ func (s *Selection) AddClass(class ...string) *Selection {
 func (s *Selection) HasClass(class string) bool {
 	class = " " + class + " "
 	for _, n := range s.Nodes {
-		classes, _ := getClassesAndAttr(n, false)
+		classes := getClasses(n)
 		if strings.Contains(classes, class) {
 			return true
 		}
@@ -164,14 +251,14 @@ func (s *Selection) RemoveClass(class ...string) *Selection {
 
 	for _, n := range s.Nodes {
 		if remove {
-			removeAttr(n, "class")
+			removeAttr(s.document, n, "class")
 		} else {
-			classes, attr := getClassesAndAttr(n, true)
+			classes := getClasses(n)
 			for _, rcl := range rclasses {
 				classes = strings.Replace(classes, " "+rcl+" ", " ", -1)
 			}
 
-			setClasses(n, attr, classes)
+			setClasses(s.document, n, classes)
 		}
 	}
 
@@ -190,7 +277,7 @@ func (s *Selection) ToggleClass(class ...string) *Selection {
 	tcls := getClassesSlice(classStr)
 
 	for _, n := range s.Nodes {
-		classes, attr := getClassesAndAttr(n, true)
+		classes := getClasses(n)
 		for _, tcl := range tcls {
 			if strings.Contains(classes, " "+tcl+" ") {
 				classes = strings.Replace(classes, " "+tcl+" ", " ", -1)
@@ -199,12 +286,42 @@ func (s *Selection) ToggleClass(class ...string) *Selection {
 			}
 		}
 
-		setClasses(n, attr, classes)
+		setClasses(s.document, n, classes)
 	}
 
 	return s
 }
 
+// writeNodes renders the nodes, or their children if inner is true, to w.
+func writeNodes(w io.Writer, nodes []*html.Node, inner bool) error {
+	// buffer the writes once for all the nodes, html.Render would otherwise
+	// do it (and flush) for each node.
+	bw, ok := w.(bufferedWriter)
+	var buf *bufio.Writer
+	if !ok {
+		buf = bufio.NewWriter(w)
+		bw = buf
+	}
+
+	for _, n := range nodes {
+		if !inner {
+			if e := html.Render(bw, n); e != nil {
+				return e
+			}
+			continue
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if e := html.Render(bw, c); e != nil {
+				return e
+			}
+		}
+	}
+	if buf != nil {
+		return buf.Flush()
+	}
+	return nil
+}
+
 func getAttributePtr(attrName string, n *html.Node) *html.Attribute {
 	if n == nil {
 		return nil
@@ -228,48 +345,287 @@ func getAttributeValue(attrName string, n *html.Node) (val string, exists bool)
 }
 
 // Get and normalize the "class" attribute from the node.
-func getClassesAndAttr(n *html.Node, create bool) (classes string, attr *html.Attribute) {
+func getClasses(n *html.Node) string {
 	// Applies only to element nodes
 	if n.Type == html.ElementNode {
-		attr = getAttributePtr("class", n)
-		if attr == nil && create {
-			n.Attr = append(n.Attr, html.Attribute{
-				Key: "class",
-				Val: "",
-			})
-			attr = &n.Attr[len(n.Attr)-1]
+		if val, ok := getAttributeValue("class", n); ok {
+			return rxClassTrim.ReplaceAllString(" "+val+" ", " ")
 		}
 	}
+	return " "
+}
+
+func getClassesSlice(classes string) []string {
+	return strings.Split(rxClassTrim.ReplaceAllString(" "+classes+" ", " "), " ")
+}
 
-	if attr == nil {
-		classes = " "
-	} else {
-		classes = rxClassTrim.ReplaceAllString(" "+attr.Val+" ", " ")
+func setClasses(d *Document, n *html.Node, classes string) {
+	classes = strings.TrimSpace(classes)
+	if classes == "" {
+		removeAttr(d, n, "class")
+		return
 	}
 
-	return
+	if n.Type == html.ElementNode {
+		setAttr(d, n, "class", classes)
+	}
 }
 
-func getClassesSlice(classes string) []string {
-	return strings.Split(rxClassTrim.ReplaceAllString(" "+classes+" ", " "), " ")
+// textWriter renders the text of nodes for TextWithOptions.
+type textWriter struct {
+	opts TextOptions
+	buf  bytes.Buffer
+
+	// the number of line breaks required before the next content
+	breaks int
+	// true if a collapsible space precedes the next content
+	space bool
+	// true at the start of a line or cell, where collapsible spaces are
+	// removed
+	noSpace bool
 }
 
-func removeAttr(n *html.Node, attrName string) {
-	for i, a := range n.Attr {
-		if a.Key == attrName {
-			n.Attr[i], n.Attr[len(n.Attr)-1], n.Attr =
-				n.Attr[len(n.Attr)-1], html.Attribute{}, n.Attr[:len(n.Attr)-1]
-			return
+func (w *textWriter) node(n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data, pre || w.opts.PreserveWhitespace)
+		return
+	case html.DocumentNode:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			w.node(c, pre)
+		}
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	if !w.opts.IncludeHidden && !isRendered(n) {
+		return
+	}
+	if n.DataAtom == atom.Br {
+		w.literal("\n")
+		return
+	}
+
+	breaks := blockBreaks[n.DataAtom]
+	w.require(breaks)
+	pre = pre || isPreformatted(n)
+	closed := n.DataAtom == atom.Details && !hasAttr(n, "open")
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if closed && !w.opts.IncludeHidden && c.DataAtom != atom.Summary {
+			continue
+		}
+		w.node(c, pre)
+	}
+	w.require(breaks)
+
+	switch n.DataAtom {
+	case atom.Td, atom.Th:
+		if hasNextCell(n) {
+			w.literal(w.opts.CellSeparator)
+		}
+	case atom.Tr:
+		if hasNextRow(n) {
+			w.literal("\n")
 		}
 	}
 }
 
-func setClasses(n *html.Node, attr *html.Attribute, classes string) {
-	classes = strings.TrimSpace(classes)
-	if classes == "" {
-		removeAttr(n, "class")
+// require requires n line breaks before the next content.
+func (w *textWriter) require(n int) {
+	if n > w.breaks {
+		w.breaks = n
+	}
+}
+
+// flush writes the pending line breaks or space before some content.
+func (w *textWriter) flush() {
+	if w.breaks > 0 {
+		if w.buf.Len() > 0 {
+			w.buf.WriteString(strings.Repeat("\n", w.breaks))
+		}
+		w.breaks = 0
+		w.space = false
+		w.noSpace = true
+	}
+	if w.space && !w.noSpace && w.buf.Len() > 0 {
+		w.buf.WriteByte(' ')
+	}
+	w.space = false
+}
+
+// text writes the content of a text node, collapsing its whitespace unless
+// preserve is true.
+func (w *textWriter) text(s string, preserve bool) {
+	if preserve {
+		if s != "" {
+			w.flush()
+			w.buf.WriteString(s)
+			w.noSpace = strings.HasSuffix(s, "\n")
+		}
 		return
 	}
 
-	attr.Val = classes
+	for i := 0; i < len(s); {
+		if isASCIISpace(s[i]) {
+			w.space = true
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && !isASCIISpace(s[j]) {
+			j++
+		}
+		w.flush()
+		w.buf.WriteString(s[i:j])
+		w.noSpace = false
+		i = j
+	}
+}
+
+// literal writes s, a line break or cell separator, removing the
+// collapsible space that precedes it.
+func (w *textWriter) literal(s string) {
+	w.space = false
+	w.flush()
+	w.buf.WriteString(s)
+	w.noSpace = true
+}
+
+func isASCIISpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+// the number of line breaks required around block elements.
+var blockBreaks = map[atom.Atom]int{
+	atom.Address:    1,
+	atom.Article:    1,
+	atom.Aside:      1,
+	atom.Blockquote: 1,
+	atom.Body:       1,
+	atom.Caption:    1,
+	atom.Center:     1,
+	atom.Dd:         1,
+	atom.Details:    1,
+	atom.Dialog:     1,
+	atom.Dir:        1,
+	atom.Div:        1,
+	atom.Dl:         1,
+	atom.Dt:         1,
+	atom.Fieldset:   1,
+	atom.Figcaption: 1,
+	atom.Figure:     1,
+	atom.Footer:     1,
+	atom.Form:       1,
+	atom.H1:         1,
+	atom.H2:         1,
+	atom.H3:         1,
+	atom.H4:         1,
+	atom.H5:         1,
+	atom.H6:         1,
+	atom.Header:     1,
+	atom.Hgroup:     1,
+	atom.Hr:         1,
+	atom.Html:       1,
+	atom.Legend:     1,
+	atom.Li:         1,
+	atom.Listing:    1,
+	atom.Main:       1,
+	atom.Menu:       1,
+	atom.Nav:        1,
+	atom.Ol:         1,
+	atom.P:          2,
+	atom.Plaintext:  1,
+	atom.Pre:        1,
+	atom.Section:    1,
+	atom.Summary:    1,
+	atom.Table:      1,
+	atom.Ul:         1,
+	atom.Xmp:        1,
+}
+
+// the elements that are never rendered.
+var hiddenElements = map[atom.Atom]bool{
+	atom.Audio:    true,
+	atom.Base:     true,
+	atom.Canvas:   true,
+	atom.Datalist: true,
+	atom.Head:     true,
+	atom.Iframe:   true,
+	atom.Link:     true,
+	atom.Meta:     true,
+	atom.Noframes: true,
+	atom.Noscript: true,
+	atom.Param:    true,
+	atom.Rp:       true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Template: true,
+	atom.Textarea: true,
+	atom.Title:    true,
+	atom.Video:    true,
+}
+
+// isRendered returns false if the element n is not rendered, based on its
+// type, hidden attribute and inline style.
+func isRendered(n *html.Node) bool {
+	if hiddenElements[n.DataAtom] || hasAttr(n, "hidden") {
+		return false
+	}
+	for _, d := range getStyleDecls(n) {
+		if d.name == "display" && strings.EqualFold(d.value, "none") {
+			return false
+		}
+	}
+	return true
+}
+
+func isPreformatted(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Pre, atom.Listing, atom.Plaintext, atom.Xmp, atom.Textarea:
+		return true
+	}
+	return false
+}
+
+func hasAttr(n *html.Node, attrName string) bool {
+	return getAttributePtr(attrName, n) != nil
+}
+
+// hasNextCell returns true if the table cell n is not the last of its row.
+func hasNextCell(n *html.Node) bool {
+	for c := n.NextSibling; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Td || c.DataAtom == atom.Th) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNextRow returns true if the table row n is not the last of its table.
+func hasNextRow(n *html.Node) bool {
+	for c := n.NextSibling; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == atom.Tr {
+			return true
+		}
+	}
+	p := n.Parent
+	if p == nil || (p.DataAtom != atom.Thead && p.DataAtom != atom.Tbody && p.DataAtom != atom.Tfoot) {
+		return false
+	}
+	for g := p.NextSibling; g != nil; g = g.NextSibling {
+		if g.Type != html.ElementNode {
+			continue
+		}
+		switch g.DataAtom {
+		case atom.Tr:
+			return true
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			if len(childElements(g, atom.Tr)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -1,6 +1,9 @@
 package goquery
 
 import (
+	"bytes"
+	"io"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -116,6 +119,56 @@ func TestHtml(t *testing.T) {
 	}
 }
 
+func TestWriteHtml(t *testing.T) {
+	doc := loadString(t, `<div><p>a<br>b</p></div><div><!-- c --><b>d</b></div><div></div>`)
+
+	var buf bytes.Buffer
+	if e := doc.Find("div").WriteHtml(&buf); e != nil {
+		t.Fatal(e)
+	}
+	if expected := `<p>a<br/>b</p><!-- c --><b>d</b>`; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteOuterHtml(t *testing.T) {
+	doc := loadString(t, `<div><p class="x">a</p><p>b &amp; c</p></div>`)
+
+	var buf bytes.Buffer
+	if e := doc.Find("p").WriteOuterHtml(&buf); e != nil {
+		t.Fatal(e)
+	}
+	if expected := `<p class="x">a</p><p>b &amp; c</p>`; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	// the writer doesn't need to be buffered
+	var sb strings.Builder
+	if e := doc.Find("p").WriteOuterHtml(struct{ io.Writer }{&sb}); e != nil {
+		t.Fatal(e)
+	}
+	if sb.String() != buf.String() {
+		t.Errorf("expected %q, got %q", buf.String(), sb.String())
+	}
+}
+
+func TestOuterHtmlAll(t *testing.T) {
+	doc := loadString(t, `<ul><li>a</li><li class="b">b</li></ul>`)
+
+	all, e := doc.Find("li").OuterHtmlAll()
+	if e != nil {
+		t.Fatal(e)
+	}
+	expected := []string{`<li>a</li>`, `<li class="b">b</li>`}
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("expected %q, got %q", expected, all)
+	}
+
+	if all, e = doc.Find("nothing").OuterHtmlAll(); e != nil || len(all) != 0 {
+		t.Errorf("expected an empty result, got %q, %v", all, e)
+	}
+}
+
 func TestNbsp(t *testing.T) {
 	src := `<p>Some&nbsp;text</p>`
 	d, err := NewDocumentFromReader(strings.NewReader(src))
@@ -250,3 +303,78 @@ func TestToggleClass(t *testing.T) {
 		t.Errorf("Expected #nf1 to have no classes, have %q", a)
 	}
 }
+
+func TestInnerText(t *testing.T) {
+	doc := loadString(t, `<html><head><title>T</title><style>p { color: red }</style></head>
+<body>
+	<h1>  Hello,
+		<em>world</em> !</h1>
+	<p>First   paragraph.</p><p>Second<br>line  <br> third</p>
+	<script>var x = 1;</script>
+	<div hidden>hidden</div><div style="color: red; DISPLAY : none">none</div>
+	<template><p>template</p></template>
+	<ul><li>one</li><li> two </li></ul>
+	<pre>  keep
+   this  </pre>
+	<span>a</span> <span>b</span>
+</body></html>`)
+
+	expected := "Hello, world !\n\nFirst paragraph.\n\nSecond\nline\nthird\n\none\ntwo\n  keep\n   this  \na b"
+	if got := doc.InnerText(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestInnerTextTable(t *testing.T) {
+	doc := loadString(t, `<table>
+		<thead><tr><th>Name</th><th>Age</th></tr></thead>
+		<tbody>
+			<tr><td> Alice </td><td>30</td></tr>
+			<tr><td></td><td>25</td></tr>
+		</tbody>
+	</table><p>after</p>`)
+
+	expected := "Name\tAge\nAlice\t30\n\t25\n\nafter"
+	if got := doc.InnerText(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestInnerTextDetails(t *testing.T) {
+	doc := loadString(t, `<details><summary>Sum</summary>closed</details>`+
+		`<details open><summary>Sum</summary>opened</details>`)
+
+	expected := "Sum\nSum\nopened"
+	if got := doc.InnerText(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestInnerTextSelection(t *testing.T) {
+	doc := loadString(t, `<div><p>a</p><script>var x;</script></div><b> b </b><i>c</i>`)
+
+	if got := doc.Find("b, i").InnerText(); got != "b c" {
+		t.Errorf("expected %q, got %q", "b c", got)
+	}
+	// elements that are not rendered return their raw text
+	if got := doc.Find("script").InnerText(); got != "var x;" {
+		t.Errorf("expected %q, got %q", "var x;", got)
+	}
+	if got := doc.Find("nope").InnerText(); got != "" {
+		t.Errorf("expected empty string, got %q", got)
+	}
+}
+
+func TestTextWithOptions(t *testing.T) {
+	doc := loadString(t, `<table><tr><td>a  b</td><td>c</td></tr></table><script>x</script>`)
+
+	expected := "a  b | c\nx"
+	got := doc.TextWithOptions(TextOptions{
+		PreserveWhitespace: true,
+		IncludeHidden:      true,
+		CellSeparator:      " | ",
+	})
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -8,23 +8,38 @@ func (s *Selection) Is(selector string) bool {
 	return s.IsMatcher(compileMatcher(selector))
 }
 
+// IsE is like Is, except that it returns an error (a *SelectorError)
+// instead of false if the selector string is invalid.
+func (s *Selection) IsE(selector string) (bool, error) {
+	m, err := Compile(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.IsMatcher(m), nil
+}
+
 // IsMatcher checks the current matched set of elements against a matcher and
-// returns true if at least one of these elements matches.
+// returns true if at least one of these elements matches. It stops at the
+// first element that matches.
 func (s *Selection) IsMatcher(m Matcher) bool {
-	if len(s.Nodes) > 0 {
-		if len(s.Nodes) == 1 {
-			return m.Match(s.Nodes[0])
+	for _, n := range s.Nodes {
+		if m.Match(n) {
+			return true
 		}
-		return len(m.Filter(s.Nodes)) > 0
 	}
-
 	return false
 }
 
 // IsFunction checks the current matched set of elements against a predicate and
-// returns true if at least one of these elements matches.
+// returns true if at least one of these elements matches. It stops at the
+// first element that matches.
 func (s *Selection) IsFunction(f func(int, *Selection) bool) bool {
-	return s.FilterFunction(f).Length() > 0
+	for i, n := range s.Nodes {
+		if f(i, newSingleSelection(n, s.document)) {
+			return true
+		}
+	}
+	return false
 }
 
 // IsSelection checks the current matched set of elements against a Selection object
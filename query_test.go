@@ -101,3 +101,39 @@ func TestSelNotContains(t *testing.T) {
 		t.Error("Expected a.link to NOT contain span tag.")
 	}
 }
+
+func TestIsE(t *testing.T) {
+	ok, err := Doc().Find(".footer p:nth-child(1)").IsE("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("Expected .footer p:nth-child(1) to be p.")
+	}
+
+	if _, err := Doc().Find(".footer p:nth-child(1)").IsE(""); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestIsFunctionShortCircuit(t *testing.T) {
+	var cnt int
+	ok := Doc().Find("div").IsFunction(func(i int, s *Selection) bool {
+		cnt++
+		return i == 1
+	})
+
+	if !ok {
+		t.Error("Expected the function to match the second div.")
+	}
+	if cnt != 2 {
+		t.Errorf("Expected the function to be called 2 times, got %d.", cnt)
+	}
+}
+
+func TestIsMatcherNotFirst(t *testing.T) {
+	sel := Doc2().Find(".row")
+	if !sel.IsMatcher(compileMatcher("#nf6")) {
+		t.Error("Expected a .row to be #nf6.")
+	}
+}
@@ -0,0 +1,542 @@
+package goquery
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Renderer renders nodes as HTML, with options to control the output. Its
+// zero value renders exactly like html.Render, as Html and OuterHtml do.
+// A Renderer is safe for concurrent use as long as its fields are not
+// modified.
+type Renderer struct {
+	// Indent, if not empty, enables pretty-printing: each element whose
+	// children are only block-level elements (e.g. <ul>, <tr> or <body>) has
+	// each of its children on its own line, indented with Indent repeated
+	// once per level, and whitespace-only text nodes between them are
+	// dropped. Elements with inline content, and preformatted elements such
+	// as <pre>, are rendered as-is.
+	Indent string
+
+	// XHTML renders polyglot markup that is also well-formed XML: void
+	// elements and empty foreign (SVG and MathML) elements are self-closed,
+	// boolean attributes with an empty value are given their name as value,
+	// the XHTML namespace is declared on the <html> element and the content
+	// of <script> and <style> elements that contain markup characters is
+	// wrapped in a commented-out CDATA section.
+	XHTML bool
+
+	// Minify renders the smallest equivalent HTML: whitespace is collapsed
+	// outside of preformatted elements and removed around block-level
+	// elements. Unless XHTML is set, optional end tags (e.g. </li> or </p>)
+	// are also dropped, attribute values are left unquoted when possible,
+	// empty attribute values are omitted and void elements are not
+	// self-closed.
+	Minify bool
+
+	// SortAttributes renders the attributes of elements sorted by namespace
+	// and name, for a deterministic output. The nodes are not modified.
+	SortAttributes bool
+}
+
+// Render renders the outer HTML of each node in the Selection to w, that
+// is, the nodes including their tags and attributes. When pretty-printing,
+// the nodes are separated by line breaks.
+func (r *Renderer) Render(w io.Writer, s *Selection) error {
+	return r.render(w, s.Nodes, false)
+}
+
+// RenderInner renders the inner HTML of the first node in the Selection to
+// w, that is, its children, like Selection.Html.
+func (r *Renderer) RenderInner(w io.Writer, s *Selection) error {
+	if len(s.Nodes) == 0 {
+		return nil
+	}
+	var children []*html.Node
+	for c := s.Nodes[0].FirstChild; c != nil; c = c.NextSibling {
+		children = append(children, c)
+	}
+	return r.render(w, children, true)
+}
+
+// bufferedWriter is the interface satisfied by the writers that don't need
+// to be buffered, like in html.Render.
+type bufferedWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+func (r *Renderer) render(w io.Writer, nodes []*html.Node, siblings bool) error {
+	bw, ok := w.(bufferedWriter)
+	var buf *bufio.Writer
+	if !ok {
+		buf = bufio.NewWriter(w)
+		bw = buf
+	}
+
+	rs := &renderState{Renderer: r, w: bw}
+	for i, n := range nodes {
+		if siblings && rs.skipText(n) {
+			continue
+		}
+		if i > 0 && r.Indent != "" && !siblings {
+			rs.writeByte('\n')
+		}
+		rs.node(n, 0)
+	}
+	if rs.err == nil && buf != nil {
+		rs.err = buf.Flush()
+	}
+	if rs.err == errPlaintextAbort {
+		return nil
+	}
+	return rs.err
+}
+
+// errPlaintextAbort is set when a <plaintext> element has been rendered, to
+// stop the rendering: nothing can follow it.
+var errPlaintextAbort = errors.New("goquery: plaintext abort")
+
+// renderState holds the state of a rendering. Write errors are sticky: once
+// an error occurred, the writes are ignored.
+type renderState struct {
+	*Renderer
+	w   bufferedWriter
+	err error
+}
+
+func (rs *renderState) writeString(s string) {
+	if rs.err == nil {
+		_, rs.err = rs.w.WriteString(s)
+	}
+}
+
+func (rs *renderState) writeByte(b byte) {
+	if rs.err == nil {
+		rs.err = rs.w.WriteByte(b)
+	}
+}
+
+// writeEscaped writes s escaped like html.Render does.
+func (rs *renderState) writeEscaped(s string) {
+	if rs.err == nil {
+		_, rs.err = htmlEscaper.WriteString(rs.w, s)
+	}
+}
+
+var htmlEscaper = strings.NewReplacer(
+	`&`, "&amp;",
+	`'`, "&#39;",
+	`<`, "&lt;",
+	`>`, "&gt;",
+	`"`, "&#34;",
+	"\r", "&#13;",
+)
+
+// newline writes a line break followed by the indentation of the depth.
+func (rs *renderState) newline(depth int) {
+	rs.writeByte('\n')
+	for i := 0; i < depth; i++ {
+		rs.writeString(rs.Indent)
+	}
+}
+
+func (rs *renderState) node(n *html.Node, depth int) {
+	if rs.err != nil {
+		return
+	}
+
+	switch n.Type {
+	case html.TextNode:
+		rs.text(n)
+	case html.DocumentNode:
+		rs.children(n, depth)
+	case html.ElementNode:
+		rs.element(n, depth)
+	default:
+		// comments, doctypes and errors are rendered like html.Render does
+		rs.err = html.Render(rs.w, n)
+	}
+}
+
+func (rs *renderState) text(n *html.Node) {
+	if rs.Minify && !inPreformatted(n) {
+		rs.writeEscaped(rs.minifiedText(n))
+		return
+	}
+	rs.writeEscaped(n.Data)
+}
+
+func (rs *renderState) element(n *html.Node, depth int) {
+	rs.startTag(n)
+
+	if voidElements[n.Data] {
+		if n.FirstChild != nil {
+			rs.err = fmt.Errorf("html: void element <%s> has child nodes", n.Data)
+		} else if rs.Minify && !rs.XHTML {
+			rs.writeByte('>')
+		} else {
+			rs.writeString("/>")
+		}
+		return
+	}
+	if rs.XHTML && n.Namespace != "" && n.FirstChild == nil {
+		rs.writeString("/>")
+		return
+	}
+	rs.writeByte('>')
+
+	// add an initial newline where there is danger of a newline being
+	// ignored
+	if c := n.FirstChild; c != nil && c.Type == html.TextNode && strings.HasPrefix(c.Data, "\n") {
+		switch n.Data {
+		case "pre", "listing", "textarea":
+			rs.writeByte('\n')
+		}
+	}
+
+	if rawTextElements[n.Data] && n.Namespace == "" {
+		rs.rawText(n)
+		if n.Data == "plaintext" {
+			// nothing else can be rendered after a <plaintext>
+			if rs.err == nil {
+				rs.err = errPlaintextAbort
+			}
+			return
+		}
+	} else {
+		rs.children(n, depth)
+	}
+
+	if !rs.omitEndTag(n) {
+		rs.writeString("</")
+		rs.writeString(n.Data)
+		rs.writeByte('>')
+	}
+}
+
+// startTag writes the start tag of the element n, without its closing
+// bracket.
+func (rs *renderState) startTag(n *html.Node) {
+	rs.writeByte('<')
+	rs.writeString(n.Data)
+
+	attrs := n.Attr
+	if rs.SortAttributes && !sort.SliceIsSorted(attrs, attrLess(attrs)) {
+		attrs = append([]html.Attribute(nil), attrs...)
+		sort.SliceStable(attrs, attrLess(attrs))
+	}
+	if rs.XHTML && n.DataAtom == atom.Html && n.Namespace == "" && getAttributePtr("xmlns", n) == nil {
+		rs.writeString(` xmlns="http://www.w3.org/1999/xhtml"`)
+	}
+
+	for _, a := range attrs {
+		rs.writeByte(' ')
+		if a.Namespace != "" {
+			rs.writeString(a.Namespace)
+			rs.writeByte(':')
+		}
+		rs.writeString(a.Key)
+
+		val := a.Val
+		switch {
+		case rs.XHTML:
+			if val == "" && a.Namespace == "" && booleanAttrs[a.Key] {
+				val = a.Key
+			}
+		case rs.Minify:
+			if val == "" {
+				continue
+			}
+			if !strings.ContainsAny(val, " \t\n\f\r\"'=<>`") {
+				rs.writeByte('=')
+				rs.writeEscaped(val)
+				continue
+			}
+		}
+		rs.writeString(`="`)
+		rs.writeEscaped(val)
+		rs.writeByte('"')
+	}
+}
+
+func attrLess(attrs []html.Attribute) func(i, j int) bool {
+	return func(i, j int) bool {
+		if attrs[i].Namespace != attrs[j].Namespace {
+			return attrs[i].Namespace < attrs[j].Namespace
+		}
+		return attrs[i].Key < attrs[j].Key
+	}
+}
+
+// rawText writes the children of the raw text element n.
+func (rs *renderState) rawText(n *html.Node) {
+	var cdata bool
+	if rs.XHTML && (n.DataAtom == atom.Script || n.DataAtom == atom.Style) {
+		text := newSingleSelection(n, nil).Text()
+		cdata = strings.ContainsAny(text, "<&") && !strings.Contains(text, "<![CDATA[")
+	}
+	if cdata {
+		if n.DataAtom == atom.Script {
+			rs.writeString("//<![CDATA[\n")
+		} else {
+			rs.writeString("/*<![CDATA[*/\n")
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			rs.writeString(c.Data)
+		} else {
+			rs.node(c, 0)
+		}
+	}
+
+	if cdata {
+		if n.DataAtom == atom.Script {
+			rs.writeString("\n//]]>")
+		} else {
+			rs.writeString("\n/*]]>*/")
+		}
+	}
+}
+
+// children writes the children of n, the element or document at depth.
+func (rs *renderState) children(n *html.Node, depth int) {
+	if rs.Indent != "" && isBlockContainer(n) {
+		// the children of a document are not indented, and not preceded by
+		// a line break for the first one
+		isDoc := n.Type == html.DocumentNode
+		childDepth := depth + 1
+		if isDoc {
+			childDepth = depth
+		}
+		first := true
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.TextNode {
+				// whitespace-only, see isBlockContainer
+				continue
+			}
+			if !isDoc || !first {
+				rs.newline(childDepth)
+			}
+			first = false
+			rs.node(c, childDepth)
+		}
+		if !isDoc {
+			rs.newline(depth)
+		}
+		return
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if rs.skipText(c) {
+			continue
+		}
+		rs.node(c, depth)
+	}
+}
+
+// skipText returns true if n is a text node that is not rendered when
+// minifying.
+func (rs *renderState) skipText(n *html.Node) bool {
+	return rs.Minify && n.Type == html.TextNode && !inPreformatted(n) && rs.minifiedText(n) == ""
+}
+
+// minifiedText returns the text of the text node n with its whitespace
+// collapsed, and removed next to block-level elements.
+func (rs *renderState) minifiedText(n *html.Node) string {
+	s := rxWhitespace.ReplaceAllString(n.Data, " ")
+	if isBlockBoundary(n.PrevSibling, n.Parent) {
+		s = strings.TrimLeft(s, " ")
+	}
+	if isBlockBoundary(n.NextSibling, n.Parent) {
+		s = strings.TrimRight(s, " ")
+	}
+	return s
+}
+
+// isBlockBoundary returns true if the sibling of a text node is a block
+// boundary, where whitespace is not significant. If there is no sibling,
+// the boundary is the parent.
+func isBlockBoundary(sibling, parent *html.Node) bool {
+	n := sibling
+	if n == nil {
+		n = parent
+	}
+	if n == nil || n.Type == html.DocumentNode {
+		return true
+	}
+	return n.Type == html.ElementNode && isBlockElement(n)
+}
+
+// isBlockContainer returns true if the children of n, an element or
+// document, can be rendered on their own lines when pretty-printing: there
+// is at least one child, they are all block-level elements, comments,
+// doctypes or whitespace-only text nodes, and n is not preformatted.
+func isBlockContainer(n *html.Node) bool {
+	if n.FirstChild == nil || (n.Type == html.ElementNode && (isPreformatted(n) || rawTextElements[n.Data])) {
+		return false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			if strings.Trim(c.Data, " \t\n\r\f") != "" {
+				return false
+			}
+		case html.ElementNode:
+			if !isBlockElement(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isBlockElement returns true if the element n is a block-level (or
+// metadata) element, around which whitespace is not significant.
+func isBlockElement(n *html.Node) bool {
+	if n.Namespace != "" {
+		return false
+	}
+	return blockBreaks[n.DataAtom] > 0 || layoutElements[n.DataAtom]
+}
+
+// inPreformatted returns true if the text node n is in a preformatted
+// element, where whitespace is significant.
+func inPreformatted(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && (isPreformatted(p) || rawTextElements[p.Data]) {
+			return true
+		}
+	}
+	return false
+}
+
+// omitEndTag returns true if the end tag of the element n can be omitted
+// when minifying.
+func (rs *renderState) omitEndTag(n *html.Node) bool {
+	if !rs.Minify || rs.XHTML || n.Namespace != "" {
+		return false
+	}
+	rule, ok := optionalEndTags[n.DataAtom]
+	if !ok {
+		return false
+	}
+
+	next := n.NextSibling
+	for next != nil && rs.skipText(next) {
+		next = next.NextSibling
+	}
+	if next == nil {
+		if n.DataAtom == atom.P && n.Parent != nil {
+			// the end tag of a paragraph is required in these elements, as
+			// their content model is transparent
+			switch n.Parent.DataAtom {
+			case atom.A, atom.Audio, atom.Del, atom.Ins, atom.Map, atom.Noscript, atom.Video:
+				return false
+			}
+		}
+		return rule.last
+	}
+	return next.Type == html.ElementNode && next.Namespace == "" && rule.next[next.DataAtom]
+}
+
+// optionalEndTag describes when the end tag of an element can be omitted:
+// if it is followed by one of the next elements, or if it is the last child
+// of its parent and last is true.
+type optionalEndTag struct {
+	next map[atom.Atom]bool
+	last bool
+}
+
+func nameSet(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	return m
+}
+
+func atomSet(atoms ...atom.Atom) map[atom.Atom]bool {
+	m := make(map[atom.Atom]bool, len(atoms))
+	for _, a := range atoms {
+		m[a] = true
+	}
+	return m
+}
+
+// the elements whose end tag can be omitted, as per the HTML specification.
+var optionalEndTags = map[atom.Atom]optionalEndTag{
+	atom.Li:       {atomSet(atom.Li), true},
+	atom.Dt:       {atomSet(atom.Dt, atom.Dd), false},
+	atom.Dd:       {atomSet(atom.Dt, atom.Dd), true},
+	atom.Rt:       {atomSet(atom.Rt, atom.Rp), true},
+	atom.Rp:       {atomSet(atom.Rt, atom.Rp), true},
+	atom.Optgroup: {atomSet(atom.Optgroup), true},
+	atom.Option:   {atomSet(atom.Option, atom.Optgroup), true},
+	atom.Thead:    {atomSet(atom.Tbody, atom.Tfoot), false},
+	atom.Tbody:    {atomSet(atom.Tbody, atom.Tfoot), true},
+	atom.Tfoot:    {nil, true},
+	atom.Tr:       {atomSet(atom.Tr), true},
+	atom.Td:       {atomSet(atom.Td, atom.Th), true},
+	atom.Th:       {atomSet(atom.Td, atom.Th), true},
+	atom.P: {atomSet(atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Details,
+		atom.Div, atom.Dl, atom.Fieldset, atom.Figcaption, atom.Figure, atom.Footer, atom.Form,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Header, atom.Hgroup, atom.Hr,
+		atom.Main, atom.Menu, atom.Nav, atom.Ol, atom.P, atom.Pre, atom.Section, atom.Table,
+		atom.Ul), true},
+}
+
+// the void elements, that can't have any content. Like in html.Render,
+// they are looked up by tag name rather than by atom, so that the nodes
+// built without DataAtom are rendered the same way.
+var voidElements = nameSet("area", "base", "br", "col", "embed", "hr", "img", "input",
+	"keygen", "link", "meta", "param", "source", "track", "wbr")
+
+// the elements whose text content is rendered unescaped, by tag name.
+var rawTextElements = nameSet("iframe", "noembed", "noframes", "noscript", "plaintext",
+	"script", "style", "xmp")
+
+// the elements that are not in blockBreaks but are laid out like block
+// elements, or not rendered at all, so whitespace around them is not
+// significant.
+var layoutElements = atomSet(atom.Head, atom.Title, atom.Meta, atom.Link, atom.Base,
+	atom.Script, atom.Style, atom.Template, atom.Noscript, atom.Thead, atom.Tbody,
+	atom.Tfoot, atom.Tr, atom.Td, atom.Th, atom.Colgroup, atom.Col, atom.Option,
+	atom.Optgroup)
+
+// the boolean attributes, rendered with their name as value in XHTML.
+var booleanAttrs = map[string]bool{
+	"allowfullscreen": true,
+	"async":           true,
+	"autofocus":       true,
+	"autoplay":        true,
+	"checked":         true,
+	"controls":        true,
+	"default":         true,
+	"defer":           true,
+	"disabled":        true,
+	"formnovalidate":  true,
+	"hidden":          true,
+	"ismap":           true,
+	"itemscope":       true,
+	"loop":            true,
+	"multiple":        true,
+	"muted":           true,
+	"nomodule":        true,
+	"novalidate":      true,
+	"open":            true,
+	"readonly":        true,
+	"required":        true,
+	"reversed":        true,
+	"selected":        true,
+}
@@ -0,0 +1,174 @@
+package goquery
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func renderString(t *testing.T, r *Renderer, sel *Selection) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if e := r.Render(&buf, sel); e != nil {
+		t.Fatal(e)
+	}
+	return buf.String()
+}
+
+func TestRendererZeroValue(t *testing.T) {
+	for _, doc := range []*Document{Doc(), Doc2(), DocB(), DocW()} {
+		var expected bytes.Buffer
+		if e := html.Render(&expected, doc.Nodes[0]); e != nil {
+			t.Fatal(e)
+		}
+		if got := renderString(t, &Renderer{}, doc.Selection); got != expected.String() {
+			t.Errorf("expected the output of html.Render, got a different output")
+		}
+	}
+}
+
+func TestRendererZeroValueNoAtom(t *testing.T) {
+	// nodes built without DataAtom, looked up by name like html.Render
+	div := &html.Node{Type: html.ElementNode, Data: "div"}
+	div.AppendChild(&html.Node{Type: html.ElementNode, Data: "br"})
+	script := &html.Node{Type: html.ElementNode, Data: "script"}
+	script.AppendChild(&html.Node{Type: html.TextNode, Data: "a < b"})
+	div.AppendChild(script)
+
+	var expected bytes.Buffer
+	if e := html.Render(&expected, div); e != nil {
+		t.Fatal(e)
+	}
+	if got := renderString(t, &Renderer{}, newSingleSelection(div, nil)); got != expected.String() {
+		t.Errorf("expected %q, got %q", expected.String(), got)
+	}
+}
+
+func TestRendererInner(t *testing.T) {
+	doc := loadString(t, `<div id="a"><p class="x">a &amp; b</p><br/>c</div>`)
+	sel := doc.Find("#a")
+
+	expected, _ := sel.Html()
+	var buf bytes.Buffer
+	if e := (&Renderer{}).RenderInner(&buf, sel); e != nil {
+		t.Fatal(e)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if e := (&Renderer{}).RenderInner(&buf, doc.Find("nothing")); e != nil || buf.Len() != 0 {
+		t.Errorf("expected no output and no error, got %q, %v", buf.String(), e)
+	}
+}
+
+func TestRendererIndent(t *testing.T) {
+	doc := loadString(t, `<!DOCTYPE html><html><head><title>T</title></head><body>
+<ul>
+	<li>one <b>bold</b></li>
+	<li><p>two</p></li>
+</ul>
+<pre>  keep
+  this</pre></body></html>`)
+
+	expected := `<!DOCTYPE html>
+<html>
+  <head>
+    <title>T</title>
+  </head>
+  <body>
+    <ul>
+      <li>one <b>bold</b></li>
+      <li>
+        <p>two</p>
+      </li>
+    </ul>
+    <pre>  keep
+  this</pre>
+  </body>
+</html>`
+	if got := renderString(t, &Renderer{Indent: "  "}, doc.Selection); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// the nodes of the selection are on their own lines
+	if got := renderString(t, &Renderer{Indent: "\t"}, doc.Find("li")); got != "<li>one <b>bold</b></li>\n<li>\n\t<p>two</p>\n</li>" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestRendererXHTML(t *testing.T) {
+	doc := loadString(t, `<html><head><script>if (a < b) {}</script><style>p {}</style></head>`+
+		`<body><input type="checkbox" checked><br><svg><path d="M0"></path></svg></body></html>`)
+
+	expected := `<html xmlns="http://www.w3.org/1999/xhtml"><head><script>//<![CDATA[
+if (a < b) {}
+//]]></script><style>p {}</style></head>` +
+		`<body><input type="checkbox" checked="checked"/><br/><svg><path d="M0"/></svg></body></html>`
+	if got := renderString(t, &Renderer{XHTML: true}, doc.Selection); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestRendererMinify(t *testing.T) {
+	doc := loadString(t, `<html><head>
+	<title> The   title </title>
+</head>
+<body>
+	<div  class="a b"  id="main">
+		<p>Some   <b>bold</b>
+		text</p>
+		<p>Other</p>
+		<ul>
+			<li>one</li>
+			<li>two</li>
+		</ul>
+		<input disabled value="">
+		<pre>  keep  </pre>
+		<a href="/x"><p>in link</p></a>
+	</div>
+	<table><tr><td>1</td><td>2</td></tr></table>
+</body></html>`)
+
+	expected := `<html><head><title>The title</title></head><body>` +
+		`<div class="a b" id=main><p>Some <b>bold</b> text<p>Other<ul><li>one<li>two</ul>` +
+		`<input disabled value><pre>  keep  </pre><a href=/x><p>in link</p></a></div>` +
+		`<table><tbody><tr><td>1<td>2</table></body></html>`
+	if got := renderString(t, &Renderer{Minify: true}, doc.Selection); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// with XHTML, only the whitespace is minified
+	expected = `<div class="a b" id="main"><p>Some <b>bold</b> text</p><p>Other</p>` +
+		`<ul><li>one</li><li>two</li></ul><input disabled="disabled" value=""/>` +
+		`<pre>  keep  </pre><a href="/x"><p>in link</p></a></div>`
+	if got := renderString(t, &Renderer{Minify: true, XHTML: true}, doc.Find("div")); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestRendererSortAttributes(t *testing.T) {
+	doc := loadString(t, `<a title="t" href="/" class="c">x</a>`)
+	a := doc.Find("a")
+
+	if got := renderString(t, &Renderer{SortAttributes: true}, a); got != `<a class="c" href="/" title="t">x</a>` {
+		t.Errorf("unexpected output %q", got)
+	}
+	// the node is not modified
+	if got := renderString(t, &Renderer{}, a); got != `<a title="t" href="/" class="c">x</a>` {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestRendererVoidWithChildren(t *testing.T) {
+	doc := loadString(t, `<br>`)
+	br := doc.Find("br")
+	br.Get(0).AppendChild(&html.Node{Type: html.TextNode, Data: "x"})
+
+	var buf bytes.Buffer
+	if e := (&Renderer{}).Render(&buf, br); e == nil {
+		t.Error("expected an error")
+	}
+}
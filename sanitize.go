@@ -0,0 +1,319 @@
+package goquery
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SanitizePolicy is an allowlist of the elements, attributes, URL schemes
+// and style properties that are kept by Sanitize. Everything else is
+// removed. Use DefaultSanitizePolicy for a hardened policy suitable for
+// user-submitted HTML, and adjust it as needed.
+type SanitizePolicy struct {
+	// Elements maps the names of the allowed elements to the names of their
+	// allowed attributes. Elements that are not allowed are replaced by
+	// their (sanitized) content, unless they are in DropElements.
+	Elements map[string][]string
+
+	// GlobalAttributes holds the names of the attributes allowed on all the
+	// allowed elements.
+	GlobalAttributes []string
+
+	// DropElements holds the names of the elements that are removed along
+	// with their content, such as <script> or <style>.
+	DropElements []string
+
+	// URLSchemes holds the allowed schemes of the URL attributes (e.g. href
+	// or src, see Document.AbsolutizeURLs). URL attributes with another
+	// scheme are removed. Relative URLs are always allowed.
+	URLSchemes []string
+
+	// StyleProperties holds the CSS properties allowed in style attributes,
+	// if the style attribute is allowed. The other declarations are removed,
+	// as well as those whose value may load resources or run code (e.g.
+	// url() or expression()).
+	StyleProperties []string
+
+	// RequireNoFollow adds "nofollow" to the rel attribute of <a> elements
+	// that have an href attribute.
+	RequireNoFollow bool
+
+	// AllowComments keeps the comments, which are otherwise removed.
+	AllowComments bool
+}
+
+// DefaultSanitizePolicy returns a new hardened SanitizePolicy, that allows
+// the formatting, list, table, quote, link and image elements, without
+// styles, classes, ids or event handlers. Links and images may only use
+// the http, https and mailto schemes, links get rel="nofollow" and
+// comments are removed.
+func DefaultSanitizePolicy() *SanitizePolicy {
+	return &SanitizePolicy{
+		Elements: map[string][]string{
+			"a":          {"href"},
+			"abbr":       nil,
+			"b":          nil,
+			"blockquote": {"cite"},
+			"br":         nil,
+			"caption":    nil,
+			"cite":       nil,
+			"code":       nil,
+			"col":        {"span"},
+			"colgroup":   {"span"},
+			"dd":         nil,
+			"del":        {"cite", "datetime"},
+			"details":    {"open"},
+			"div":        nil,
+			"dl":         nil,
+			"dt":         nil,
+			"em":         nil,
+			"figcaption": nil,
+			"figure":     nil,
+			"h1":         nil,
+			"h2":         nil,
+			"h3":         nil,
+			"h4":         nil,
+			"h5":         nil,
+			"h6":         nil,
+			"hr":         nil,
+			"i":          nil,
+			"img":        {"src", "alt", "width", "height"},
+			"ins":        {"cite", "datetime"},
+			"kbd":        nil,
+			"li":         nil,
+			"mark":       nil,
+			"ol":         {"start", "reversed", "type"},
+			"p":          nil,
+			"pre":        nil,
+			"q":          {"cite"},
+			"s":          nil,
+			"samp":       nil,
+			"small":      nil,
+			"span":       nil,
+			"strong":     nil,
+			"sub":        nil,
+			"summary":    nil,
+			"sup":        nil,
+			"table":      nil,
+			"tbody":      nil,
+			"td":         {"colspan", "rowspan", "headers"},
+			"tfoot":      nil,
+			"th":         {"colspan", "rowspan", "headers", "scope"},
+			"thead":      nil,
+			"time":       {"datetime"},
+			"tr":         nil,
+			"u":          nil,
+			"ul":         nil,
+		},
+		GlobalAttributes: []string{"dir", "lang", "title"},
+		DropElements: []string{
+			"applet", "base", "embed", "frame", "frameset", "head", "iframe",
+			"link", "math", "meta", "noembed", "noframes", "noscript", "object",
+			"plaintext", "script", "select", "style", "svg", "template",
+			"textarea", "title", "xmp",
+		},
+		URLSchemes:      []string{"http", "https", "mailto"},
+		RequireNoFollow: true,
+	}
+}
+
+// Sanitize sanitizes in place the descendants of each node in the
+// Selection according to the policy, and returns the Selection. The nodes
+// of the Selection themselves are kept as-is, so to sanitize a whole
+// document, call it with the document's <body> element, e.g.
+//
+//	p.Sanitize(doc.Find("body"))
+//
+// Called with the Document itself, the <html> and <body> elements are
+// replaced by their content unless the policy allows them.
+func (p *SanitizePolicy) Sanitize(s *Selection) *Selection {
+	sz := &sanitizer{
+		policy:   p,
+		doc:      s.document,
+		elements: make(map[string]map[string]bool, len(p.Elements)),
+		global:   stringSet(p.GlobalAttributes),
+		drop:     stringSet(p.DropElements),
+		schemes:  stringSet(p.URLSchemes),
+		styles:   stringSet(p.StyleProperties),
+	}
+	for el, attrs := range p.Elements {
+		sz.elements[el] = stringSet(attrs)
+	}
+
+	for _, n := range s.Nodes {
+		sz.children(n)
+	}
+	return s
+}
+
+// sanitizer holds the lookup sets of a policy during a sanitization.
+type sanitizer struct {
+	policy   *SanitizePolicy
+	doc      *Document
+	elements map[string]map[string]bool
+	global   map[string]bool
+	drop     map[string]bool
+	schemes  map[string]bool
+	styles   map[string]bool
+}
+
+// children sanitizes the children of n.
+func (sz *sanitizer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		// the next sibling is saved before c is removed or unwrapped, the
+		// content of an unwrapped element is sanitized before and inserted
+		// before the next sibling.
+		next := c.NextSibling
+		sz.node(c)
+		c = next
+	}
+}
+
+func (sz *sanitizer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode, html.DoctypeNode:
+		return
+	case html.ElementNode:
+	case html.CommentNode:
+		if sz.policy.AllowComments {
+			return
+		}
+		fallthrough
+	default:
+		newSingleSelection(n, sz.doc).Remove()
+		return
+	}
+
+	name := strings.ToLower(n.Data)
+	if sz.drop[name] {
+		newSingleSelection(n, sz.doc).Remove()
+		return
+	}
+
+	sz.children(n)
+	attrs, ok := sz.elements[name]
+	if !ok {
+		// replace the element by its content
+		sel := newSingleSelection(n, sz.doc)
+		sel.ReplaceWithSelection(sel.Contents())
+		return
+	}
+	sz.attributes(n, attrs)
+}
+
+// attributes removes the attributes of the element n that are not allowed.
+func (sz *sanitizer) attributes(n *html.Node, allowed map[string]bool) {
+	kept := make([]html.Attribute, 0, len(n.Attr))
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" || (!allowed[key] && !sz.global[key]) {
+			continue
+		}
+
+		switch {
+		case key == "srcset":
+			if !sz.allowedSrcset(a.Val) {
+				continue
+			}
+		case key == "style":
+			if a.Val = sz.style(a.Val); a.Val == "" {
+				continue
+			}
+		case isElementURLAttr(n, key):
+			if !sz.allowedURL(a.Val) {
+				continue
+			}
+		}
+		kept = append(kept, a)
+	}
+	setAttrs(sz.doc, n, kept)
+
+	if sz.policy.RequireNoFollow && n.Data == "a" && getAttributePtr("href", n) != nil {
+		rel, _ := getAttributeValue("rel", n)
+		if !containsToken(rel, "nofollow") {
+			setAttr(sz.doc, n, "rel", strings.TrimSpace(rel+" nofollow"))
+		}
+	}
+}
+
+// allowedURL returns true if the URL is relative or has an allowed scheme.
+// The URL is normalized like browsers do before looking for its scheme, so
+// that obfuscated schemes such as "java\tscript:" are detected.
+func (sz *sanitizer) allowedURL(u string) bool {
+	u = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, u)
+	u = strings.TrimFunc(u, func(r rune) bool { return r <= ' ' })
+
+	i := strings.IndexAny(u, ":/?#")
+	if i <= 0 || u[i] != ':' {
+		// relative URL
+		return true
+	}
+	return sz.schemes[strings.ToLower(u[:i])]
+}
+
+// allowedSrcset returns true if all the URLs of the srcset are allowed.
+func (sz *sanitizer) allowedSrcset(srcset string) bool {
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 && !sz.allowedURL(fields[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// style returns the declarations of the style attribute's value that are
+// allowed, or an empty string if there are none.
+func (sz *sanitizer) style(style string) string {
+	var kept []string
+	for _, decl := range splitDeclarations(style) {
+		i := strings.IndexByte(decl, ':')
+		if i < 0 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(decl[:i]))
+		val := strings.TrimSpace(decl[i+1:])
+		if !sz.styles[prop] || val == "" || !safeStyleValue(val) {
+			continue
+		}
+		kept = append(kept, prop+": "+val)
+	}
+	return strings.Join(kept, "; ")
+}
+
+// safeStyleValue returns false if the CSS value may load resources, run
+// code or hide such constructs with escapes or comments.
+func safeStyleValue(val string) bool {
+	v := strings.ToLower(strings.Join(strings.Fields(val), ""))
+	for _, bad := range []string{"url(", "image(", "image-set(", "expression", "javascript:",
+		"vbscript:", "behavior", "binding", "@import", "\\", "/*", "<", ">"} {
+		if strings.Contains(v, bad) {
+			return false
+		}
+	}
+	return true
+}
+
+func stringSet(values []string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[strings.ToLower(v)] = true
+	}
+	return m
+}
+
+// containsToken returns true if the space-separated list of tokens
+// contains tok, ignoring case.
+func containsToken(list, tok string) bool {
+	for _, t := range strings.Fields(list) {
+		if strings.EqualFold(t, tok) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package goquery
+
+import (
+	"strings"
+	"testing"
+)
+
+// sanitizeBody sanitizes the body of the HTML document with the policy and
+// returns the resulting body content.
+func sanitizeBody(t *testing.T, p *SanitizePolicy, src string) string {
+	t.Helper()
+	body := loadString(t, src).Find("body")
+	p.Sanitize(body)
+	h, e := body.Html()
+	if e != nil {
+		t.Fatal(e)
+	}
+	return h
+}
+
+func TestSanitizeDefault(t *testing.T) {
+	got := sanitizeBody(t, DefaultSanitizePolicy(), `<h1 id="x" class="c">Title</h1>
+<p onclick="evil()" title="t">Some <b>bold</b> and <font color="red">red</font> text.<!-- comment --></p>
+<a href="https://example.com/" target="_blank" rel="author">link</a>
+<img src="/a.png" alt="A" onerror="evil()">
+<script>evil()</script><style>p {}</style>`)
+
+	expected := `<h1>Title</h1>
+<p title="t">Some <b>bold</b> and red text.</p>
+<a href="https://example.com/" rel="nofollow">link</a>
+<img src="/a.png" alt="A"/>
+`
+	if got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestSanitizeXSSVectors(t *testing.T) {
+	vectors := []string{
+		`<script>alert(1)</script>`,
+		`<SCRIPT SRC=//evil.example/xss.js></SCRIPT>`,
+		`<img src=x onerror=alert(1)>`,
+		`<img src="javascript:alert(1)">`,
+		`<img src="jav&#x09;ascript:alert(1)">`,
+		`<img src=" &#14;  javascript:alert(1)">`,
+		`<a href="JaVaScRiPt:alert(1)">x</a>`,
+		`<a href="java&#10;script:alert(1)">x</a>`,
+		`<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`,
+		`<a href="vbscript:msgbox(1)">x</a>`,
+		`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+		`<img srcset="ok.png 1x, javascript:alert(1) 2x">`,
+		`<svg onload=alert(1)><script>alert(1)</script></svg>`,
+		`<math><mtext><table><mglyph><style><img src=x onerror=alert(1)></style></mglyph></table></mtext></math>`,
+		`<iframe src="javascript:alert(1)"></iframe>`,
+		`<iframe srcdoc="<script>alert(1)</script>"></iframe>`,
+		`<object data="javascript:alert(1)"></object>`,
+		`<embed src="javascript:alert(1)">`,
+		`<form action="javascript:alert(1)"><button formaction="javascript:alert(1)">x</button></form>`,
+		`<body onload=alert(1)>`,
+		`<div style="background:url(javascript:alert(1))">x</div>`,
+		`<div style="width: expression(alert(1))">x</div>`,
+		`<a href="#" onmouseover="alert(1)">x</a>`,
+		`<noscript><p title="</noscript><img src=x onerror=alert(1)>"></noscript>`,
+		`<template><script>alert(1)</script></template>`,
+		`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+		`<base href="javascript:alert(1)//">`,
+		`<link rel="stylesheet" href="javascript:alert(1)">`,
+		`<!--[if gte IE 4]><script>alert(1)</script><![endif]-->`,
+		`<textarea><script>alert(1)</script></textarea>`,
+		`<xmp><script>alert(1)</script></xmp>`,
+		`<details open ontoggle=alert(1)>`,
+		`<video><source onerror="alert(1)"></video>`,
+		`<input autofocus onfocus=alert(1)>`,
+		`<isindex action="javascript:alert(1)" type=image>`,
+		`<a href="/relative" xlink:href="javascript:alert(1)">x</a>`,
+	}
+
+	p := DefaultSanitizePolicy()
+	for _, v := range vectors {
+		got := strings.ToLower(sanitizeBody(t, p, v))
+		for _, bad := range []string{"<script", "javascript", "vbscript", "data:", "alert(1)",
+			"onerror", "onload", "onmouseover", "onfocus", "ontoggle", "<iframe", "<svg",
+			"<math", "<object", "<embed", "<style", "<meta", "<base", "<link", "expression",
+			"xlink", "<!--", "<form", "<input", "formaction"} {
+			if strings.Contains(got, bad) {
+				t.Errorf("%s: found %q in sanitized output %q", v, bad, got)
+			}
+		}
+	}
+}
+
+func TestSanitizeCustomPolicy(t *testing.T) {
+	p := &SanitizePolicy{
+		Elements: map[string][]string{
+			"div": {"style"},
+			"a":   {"href", "rel"},
+		},
+		GlobalAttributes: []string{"class"},
+		URLSchemes:       []string{"https"},
+		StyleProperties:  []string{"color", "text-align"},
+		AllowComments:    true,
+	}
+
+	got := sanitizeBody(t, p, `<div class="x" id="y" style="COLOR: red; position: fixed; text-align:center; color: url(x)"><!-- c -->`+
+		`<a href="http://example.com" rel="author">http</a><a href="https://example.com">https</a><span>s</span></div>`+
+		`<div style="position: fixed">d</div>`)
+
+	expected := `<div class="x" style="color: red; text-align: center"><!-- c -->` +
+		`<a rel="author">http</a><a href="https://example.com">https</a>s</div><div>d</div>`
+	if got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestSanitizeStyleSemicolons(t *testing.T) {
+	p := &SanitizePolicy{
+		Elements:        map[string][]string{"div": {"style"}},
+		StyleProperties: []string{"font-family", "color"},
+	}
+
+	got := sanitizeBody(t, p, `<div style='font-family: "a;b", serif; color: red; background: url("x;color:blue")'>x</div>`)
+	expected := `<div style="font-family: &#34;a;b&#34;, serif; color: red">x</div>`
+	if got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestSanitizeNestedUnwrap(t *testing.T) {
+	got := sanitizeBody(t, DefaultSanitizePolicy(), `<section><article><p>a</p><font><b>b</b><blink>c</blink></font></article></section>d`)
+
+	if expected := `<p>a</p><b>b</b>cd`; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSanitizeDocument(t *testing.T) {
+	doc := loadString(t, `<html><head><title>t</title><script>x()</script></head><body><p>p</p></body></html>`)
+	DefaultSanitizePolicy().Sanitize(doc.Selection)
+
+	h, e := OuterHtml(doc.Selection)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if expected := `<p>p</p>`; h != expected {
+		t.Errorf("expected %q, got %q", expected, h)
+	}
+}
@@ -0,0 +1,325 @@
+package goquery
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Css gets the value of the CSS property prop in the inline style (the
+// style attribute) of the first element in the Selection. It returns an
+// empty string if the Selection is empty or if the property is not set.
+//
+// The style attribute is parsed as a CSS declaration list: comments are
+// ignored, the quoted strings and url() values are kept intact, and if a
+// property is declared more than once, the last declaration wins unless an
+// earlier one is !important. The returned value does not include the
+// !important flag, and its whitespace is collapsed (e.g. for
+// "background: url('a b.png')  no-repeat", it returns
+// "url('a b.png') no-repeat").
+//
+// Property names are case-insensitive, except for custom properties
+// (--name), and can also be given in camel case (e.g. backgroundImage for
+// background-image). Only the inline style is considered, there is no CSS
+// engine to compute the style from the stylesheets of the document.
+func (s *Selection) Css(prop string) string {
+	if len(s.Nodes) == 0 {
+		return ""
+	}
+	prop = cssPropertyName(prop)
+	for _, d := range getStyleDecls(s.Nodes[0]) {
+		if d.name == prop {
+			return d.value
+		}
+	}
+	return ""
+}
+
+// Styles returns the inline style of the first element in the Selection,
+// as a map of the CSS property names to their values, parsed as described
+// for Css. It returns nil if the Selection is empty.
+func (s *Selection) Styles() map[string]string {
+	if len(s.Nodes) == 0 {
+		return nil
+	}
+	decls := getStyleDecls(s.Nodes[0])
+	m := make(map[string]string, len(decls))
+	for _, d := range decls {
+		m[d.name] = d.value
+	}
+	return m
+}
+
+// SetCss sets the CSS property prop to val in the inline style of each
+// element in the set of matched elements. The value may end with
+// !important, and is truncated at the first semicolon that is not in a
+// string or in parentheses. An existing declaration of the property is
+// replaced in place, otherwise the declaration is added at the end. If val
+// is empty, the property is removed, like RemoveCss.
+//
+// The style attribute is re-serialized in a canonical form, with each
+// declaration written as "name: value;" (with " !important" before the
+// semicolon if it is important), separated by spaces, and the comments
+// and invalid declarations removed. It returns the Selection.
+func (s *Selection) SetCss(prop, val string) *Selection {
+	prop = cssPropertyName(prop)
+	value, important := splitImportant(collapseCSSSpace(val))
+	if prop == "" || value == "" {
+		return s.RemoveCss(prop)
+	}
+
+	for _, n := range s.Nodes {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		decls := getStyleDecls(n)
+		found := false
+		for i := range decls {
+			if decls[i].name == prop {
+				decls[i].value, decls[i].important = value, important
+				found = true
+				break
+			}
+		}
+		if !found {
+			decls = append(decls, styleDecl{name: prop, value: value, important: important})
+		}
+		setStyleDecls(s.document, n, decls)
+	}
+	return s
+}
+
+// RemoveCss removes the CSS property prop from the inline style of each
+// element in the set of matched elements. The style attribute is
+// re-serialized as described for SetCss, and removed if no declaration is
+// left. It returns the Selection.
+func (s *Selection) RemoveCss(prop string) *Selection {
+	prop = cssPropertyName(prop)
+	if prop == "" {
+		return s
+	}
+	for _, n := range s.Nodes {
+		if !hasAttr(n, "style") {
+			continue
+		}
+		decls := getStyleDecls(n)
+		kept := decls[:0]
+		for _, d := range decls {
+			if d.name != prop {
+				kept = append(kept, d)
+			}
+		}
+		if len(kept) < len(decls) {
+			setStyleDecls(s.document, n, kept)
+		}
+	}
+	return s
+}
+
+// styleDecl is a declaration of an inline style.
+type styleDecl struct {
+	name      string
+	value     string
+	important bool
+}
+
+func getStyleDecls(n *html.Node) []styleDecl {
+	style, ok := getAttributeValue("style", n)
+	if !ok {
+		return nil
+	}
+	return parseStyle(style)
+}
+
+// setStyleDecls sets the style attribute of n to the serialized decls, or
+// removes it if there is none. The attribute is left as-is if it is
+// unchanged.
+func setStyleDecls(d *Document, n *html.Node, decls []styleDecl) {
+	if len(decls) == 0 {
+		removeAttr(d, n, "style")
+		return
+	}
+	style := serializeStyle(decls)
+	if cur, ok := getAttributeValue("style", n); !ok || cur != style {
+		setAttr(d, n, "style", style)
+	}
+}
+
+func serializeStyle(decls []styleDecl) string {
+	var buf strings.Builder
+	for i, d := range decls {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(d.name)
+		buf.WriteString(": ")
+		buf.WriteString(d.value)
+		if d.important {
+			buf.WriteString(" !important")
+		}
+		buf.WriteByte(';')
+	}
+	return buf.String()
+}
+
+// parseStyle parses the CSS declaration list style. The declarations are
+// returned in order, with a single declaration per property: a duplicate
+// declaration replaces the previous one and is moved to its position, as
+// the CSSOM does, unless the previous one is important and it isn't.
+// Invalid declarations are skipped.
+func parseStyle(style string) []styleDecl {
+	var decls []styleDecl
+	for _, raw := range splitDeclarations(style) {
+		i := strings.IndexByte(raw, ':')
+		if i < 0 {
+			continue
+		}
+		name := cssPropertyName(strings.TrimSpace(raw[:i]))
+		value, important := splitImportant(strings.TrimSpace(raw[i+1:]))
+		if name == "" || value == "" || strings.ContainsAny(name, " \"'(") {
+			continue
+		}
+
+		skip := false
+		for j, d := range decls {
+			if d.name == name {
+				if d.important && !important {
+					skip = true
+				} else {
+					decls = append(decls[:j], decls[j+1:]...)
+				}
+				break
+			}
+		}
+		if !skip {
+			decls = append(decls, styleDecl{name: name, value: value, important: important})
+		}
+	}
+	return decls
+}
+
+// splitDeclarations splits the CSS declaration list style on the
+// semicolons that are not in a string or in parentheses, removing the
+// comments and collapsing the whitespace.
+func splitDeclarations(style string) []string {
+	var (
+		decls []string
+		buf   strings.Builder
+		depth int
+		quote byte
+		inURL bool
+	)
+	for i := 0; i < len(style); i++ {
+		c := style[i]
+		switch {
+		case quote != 0:
+			buf.WriteByte(c)
+			if c == '\\' && i+1 < len(style) {
+				i++
+				buf.WriteByte(style[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case inURL:
+			// the content of an unquoted url() is kept as-is
+			buf.WriteByte(c)
+			if c == '\\' && i+1 < len(style) {
+				i++
+				buf.WriteByte(style[i])
+			} else if c == ')' {
+				inURL = false
+				depth--
+			}
+		case c == '/' && i+1 < len(style) && style[i+1] == '*':
+			end := strings.Index(style[i+2:], "*/")
+			if end < 0 {
+				i = len(style)
+			} else {
+				i += end + 3
+			}
+			writeCSSSpace(&buf)
+		case c == '"' || c == '\'':
+			quote = c
+			buf.WriteByte(c)
+		case c == '(':
+			depth++
+			buf.WriteByte(c)
+			if s := buf.String(); len(s) >= 4 && strings.EqualFold(s[len(s)-4:], "url(") && isUnquotedURL(style[i+1:]) {
+				inURL = true
+			}
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+			buf.WriteByte(c)
+		case c == ';' && depth == 0:
+			decls = append(decls, buf.String())
+			buf.Reset()
+		case isASCIISpace(c):
+			writeCSSSpace(&buf)
+		case c == '\\' && i+1 < len(style):
+			buf.WriteByte(c)
+			i++
+			buf.WriteByte(style[i])
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return append(decls, buf.String())
+}
+
+// isUnquotedURL returns true if s, the content that follows "url(", is not
+// a quoted string.
+func isUnquotedURL(s string) bool {
+	s = strings.TrimLeft(s, " \t\n\r\f")
+	return s == "" || (s[0] != '"' && s[0] != '\'')
+}
+
+// writeCSSSpace writes a single space to buf, unless it already ends with
+// one.
+func writeCSSSpace(buf *strings.Builder) {
+	if s := buf.String(); s != "" && s[len(s)-1] != ' ' {
+		buf.WriteByte(' ')
+	}
+}
+
+// collapseCSSSpace removes the comments of the CSS value val and collapses
+// its whitespace. The value is truncated at the first semicolon that is not
+// in a string or in parentheses, so that it cannot add declarations.
+func collapseCSSSpace(val string) string {
+	return strings.TrimSpace(splitDeclarations(val)[0])
+}
+
+// splitImportant returns the CSS value val without its !important flag,
+// and whether it had one.
+func splitImportant(val string) (string, bool) {
+	i := strings.LastIndexByte(val, '!')
+	if i < 0 || !strings.EqualFold(strings.TrimSpace(val[i+1:]), "important") {
+		return val, false
+	}
+	return strings.TrimSpace(val[:i]), true
+}
+
+// cssPropertyName normalizes the CSS property name prop: custom properties
+// are kept as-is, the other names are lowercased, and hyphenated if they
+// are in camel case (starting with a lowercase letter).
+func cssPropertyName(prop string) string {
+	prop = strings.TrimSpace(prop)
+	if strings.HasPrefix(prop, "--") {
+		return prop
+	}
+	if prop == "" || prop[0] < 'a' || prop[0] > 'z' || strings.IndexByte(prop, '-') >= 0 {
+		return strings.ToLower(prop)
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(prop); i++ {
+		c := prop[i]
+		if 'A' <= c && c <= 'Z' {
+			buf.WriteByte('-')
+			c += 'a' - 'A'
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
@@ -0,0 +1,97 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCss(t *testing.T) {
+	doc := loadString(t, `<div style="COLOR: red; /* comment; */ background: url(data:image/png;base64,AAA=)  no-repeat;
+		font-family: 'a;b', serif; color: blue !IMPORTANT; color: green; --Custom: 1; margin:; bad; width: 1px"></div>`)
+	sel := doc.Find("div")
+
+	cases := map[string]string{
+		"color":        "blue",
+		"background":   "url(data:image/png;base64,AAA=) no-repeat",
+		"font-family":  "'a;b', serif",
+		"fontFamily":   "'a;b', serif",
+		"--Custom":     "1",
+		"--custom":     "",
+		"margin":       "",
+		"width":        "1px",
+		"missing-prop": "",
+	}
+	for prop, want := range cases {
+		if got := sel.Css(prop); got != want {
+			t.Errorf("%s: want %q, got %q", prop, want, got)
+		}
+	}
+
+	want := map[string]string{
+		"color":       "blue",
+		"background":  "url(data:image/png;base64,AAA=) no-repeat",
+		"font-family": "'a;b', serif",
+		"--Custom":    "1",
+		"width":       "1px",
+	}
+	if got := sel.Styles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if doc.Find("p").Css("color") != "" || doc.Find("p").Styles() != nil {
+		t.Error("expected no style for an empty selection")
+	}
+}
+
+func TestSetCss(t *testing.T) {
+	doc := loadString(t, `<p style="color: red; /* x */ width:1px">a</p><p>b</p>`)
+	sel := doc.Find("p")
+
+	sel.SetCss("color", "blue  !important").SetCss("backgroundImage", `url("a b.png")`)
+	want := []string{
+		`color: blue !important; width: 1px; background-image: url("a b.png");`,
+		`color: blue !important; background-image: url("a b.png");`,
+	}
+	for i, w := range want {
+		if got, _ := sel.Eq(i).Attr("style"); got != w {
+			t.Errorf("%d: want %q, got %q", i, w, got)
+		}
+	}
+
+	sel.SetCss("width", "2px; display: none")
+	if got := sel.First().Css("width"); got != "2px" {
+		t.Errorf("want 2px, got %q", got)
+	}
+	if got := sel.First().Css("display"); got != "" {
+		t.Errorf("expected no display declaration, got %q", got)
+	}
+
+	sel.SetCss("width", "").RemoveCss("color").RemoveCss("background-image")
+	for i := range want {
+		if style, ok := sel.Eq(i).Attr("style"); ok {
+			t.Errorf("%d: expected no style attribute, got %q", i, style)
+		}
+	}
+}
+
+func TestCssNoChange(t *testing.T) {
+	doc := loadString(t, `<html><body><div style="COLOR:red;   margin : 0"></div><p></p></body></html>`)
+
+	var n int
+	disconnect := doc.Observe(func(MutationRecord) { n++ })
+	defer disconnect()
+
+	doc.Find("div").RemoveCss("padding").SetCss("", "1px")
+	doc.Find("p").RemoveCss("color")
+	if n != 0 {
+		t.Errorf("expected no change, got %d", n)
+	}
+	if got, _ := doc.Find("div").Attr("style"); got != "COLOR:red;   margin : 0" {
+		t.Errorf("expected the style to be unchanged, got %q", got)
+	}
+
+	doc.Find("div").SetCss("color", "red")
+	doc.Find("div").SetCss("color", "red")
+	if n != 1 {
+		t.Errorf("expected a single change, got %d", n)
+	}
+}
@@ -0,0 +1,322 @@
+package goquery
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// limits on colspan and rowspan, as per the HTML specification.
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// Table holds the content of an HTML <table> element as a grid of strings,
+// where each cell spanning multiple columns or rows (with the colspan and
+// rowspan attributes) is repeated in all the grid positions it covers. All
+// rows have the same number of columns, missing cells are empty strings.
+//
+// The value of a cell is its text, with whitespace collapsed and trimmed.
+// Only the rows of the table itself are considered, the rows of tables
+// nested in its cells are not, although their text is part of the value of
+// the cell that contains them.
+type Table struct {
+	// Header holds the header of the table: the rows of the <thead> element,
+	// or if there is none, the first row if it only has <th> cells. When the
+	// header has multiple rows, the values of each column are joined with a
+	// space, skipping repeated values. It is nil if the table has no header.
+	Header []string
+
+	// Rows holds the body rows of the table, those of the <tbody> elements and
+	// the rows that are direct children of the <table>, in document order.
+	// Cells that are <th> elements in those rows (row headers) are kept as
+	// regular cells.
+	Rows [][]string
+
+	// Footer holds the rows of the <tfoot> element.
+	Footer [][]string
+}
+
+// NewTable returns the Table for the first element of the Selection, which
+// should be a <table> element. If it is not, the first <table> descendant of
+// that element is used instead. It returns nil if there is no table.
+func NewTable(sel *Selection) *Table {
+	if len(sel.Nodes) == 0 {
+		return nil
+	}
+	n := sel.Nodes[0]
+	if n.Type != html.ElementNode || n.DataAtom != atom.Table {
+		if n = findFirstWithMatcher(n, tableMatcher); n == nil {
+			return nil
+		}
+	}
+
+	var t Table
+	var header [][]string
+	var body []*html.Node
+	flushBody := func() {
+		t.Rows = append(t.Rows, tableGrid(body)...)
+		body = nil
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Thead:
+			flushBody()
+			header = append(header, tableGrid(childElements(c, atom.Tr))...)
+		case atom.Tfoot:
+			flushBody()
+			t.Footer = append(t.Footer, tableGrid(childElements(c, atom.Tr))...)
+		case atom.Tbody:
+			flushBody()
+			t.Rows = append(t.Rows, tableGrid(childElements(c, atom.Tr))...)
+		case atom.Tr:
+			// consecutive rows that are direct children of the table form an
+			// implicit row group
+			body = append(body, c)
+		}
+	}
+	flushBody()
+
+	if header == nil && len(t.Rows) > 0 && isHeaderRow(firstRow(n)) {
+		header, t.Rows = t.Rows[:1], t.Rows[1:]
+	}
+	if header != nil {
+		t.Header = joinHeaderRows(header)
+	}
+	t.normalize()
+	return &t
+}
+
+// matches the first table of the selection.
+var tableMatcher = compileMatcher("table")
+
+// Records returns the body rows of the table as maps keyed by the header of
+// each column (see Keys). The footer rows are not included.
+func (t *Table) Records() []map[string]string {
+	keys := t.Keys()
+	recs := make([]map[string]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		rec := make(map[string]string, len(keys))
+		for i, v := range row {
+			rec[keys[i]] = v
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+// Keys returns the keys of the records returned by Records: the header of
+// each column, where empty headers are replaced by "columnN" (N being the
+// 1-based index of the column) and duplicate headers get a "_N" suffix (N
+// being the 1-based count of that header so far), so that all keys are
+// unique.
+func (t *Table) Keys() []string {
+	n := t.width()
+	keys := make([]string, n)
+	seen := make(map[string]int, n)
+	for i := range keys {
+		k := ""
+		if i < len(t.Header) {
+			k = t.Header[i]
+		}
+		if k == "" {
+			k = "column" + strconv.Itoa(i+1)
+		}
+		if seen[k]++; seen[k] > 1 {
+			k += "_" + strconv.Itoa(seen[k])
+		}
+		keys[i] = k
+	}
+	return keys
+}
+
+// WriteCSV writes the header, if any, and the body rows of the table to w
+// in CSV format. The footer rows are not included.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if t.Header != nil {
+		if e := cw.Write(t.Header); e != nil {
+			return e
+		}
+	}
+	if e := cw.WriteAll(t.Rows); e != nil {
+		return e
+	}
+	return cw.Error()
+}
+
+// WriteJSON writes the records of the table (see Records) to w as a JSON
+// array of objects.
+func (t *Table) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t.Records())
+}
+
+// width returns the number of columns of the table.
+func (t *Table) width() int {
+	n := len(t.Header)
+	for _, rows := range [][][]string{t.Rows, t.Footer} {
+		for _, row := range rows {
+			if len(row) > n {
+				n = len(row)
+			}
+		}
+	}
+	return n
+}
+
+// normalize pads all rows to the width of the table.
+func (t *Table) normalize() {
+	n := t.width()
+	pad := func(row []string) []string {
+		for len(row) < n {
+			row = append(row, "")
+		}
+		return row
+	}
+	if t.Header != nil {
+		t.Header = pad(t.Header)
+	}
+	for _, rows := range [][][]string{t.Rows, t.Footer} {
+		for i := range rows {
+			rows[i] = pad(rows[i])
+		}
+	}
+}
+
+// tableGrid returns the grid of cell values of the rows of a row group,
+// expanding the cells that span multiple columns and rows.
+func tableGrid(rows []*html.Node) [][]string {
+	grid := make([][]string, len(rows))
+	// set to true for the positions filled by a rowspan from a previous row
+	filled := make([][]bool, len(rows))
+
+	for r, tr := range rows {
+		col := 0
+		for _, td := range childElements(tr, atom.Td, atom.Th) {
+			for col < len(filled[r]) && filled[r][col] {
+				col++
+			}
+
+			colspan := spanAttr(td, "colspan", 1, maxColspan)
+			rowspan := spanAttr(td, "rowspan", 1, maxRowspan)
+			if rowspan == 0 {
+				// spans until the end of the row group
+				rowspan = len(rows) - r
+			}
+			val := strings.Join(strings.Fields(newSingleSelection(td, nil).Text()), " ")
+
+			for y := r; y < r+rowspan && y < len(rows); y++ {
+				for x := col; x < col+colspan; x++ {
+					for len(grid[y]) <= x {
+						grid[y] = append(grid[y], "")
+						filled[y] = append(filled[y], false)
+					}
+					grid[y][x] = val
+					filled[y][x] = true
+				}
+			}
+			col += colspan
+		}
+	}
+	return grid
+}
+
+// joinHeaderRows merges the rows of the header in a single row, joining the
+// distinct values of each column with a space.
+func joinHeaderRows(rows [][]string) []string {
+	var header []string
+	for _, row := range rows {
+		for i, v := range row {
+			if i >= len(header) {
+				header = append(header, v)
+				continue
+			}
+			if v != "" && v != header[i] && !strings.HasSuffix(header[i], " "+v) {
+				if header[i] == "" {
+					header[i] = v
+				} else {
+					header[i] += " " + v
+				}
+			}
+		}
+	}
+	return header
+}
+
+// firstRow returns the first body row of the table, the first of its rows
+// in Rows, or nil. Empty <tbody> elements are skipped.
+func firstRow(table *html.Node) *html.Node {
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Tr:
+			return c
+		case atom.Tbody:
+			if rows := childElements(c, atom.Tr); len(rows) > 0 {
+				return rows[0]
+			}
+		}
+	}
+	return nil
+}
+
+// isHeaderRow returns true if the row only has <th> cells.
+func isHeaderRow(tr *html.Node) bool {
+	if tr == nil {
+		return false
+	}
+	cells := childElements(tr, atom.Td, atom.Th)
+	for _, c := range cells {
+		if c.DataAtom != atom.Th {
+			return false
+		}
+	}
+	return len(cells) > 0
+}
+
+// childElements returns the children of n that are elements of one of the
+// specified types.
+func childElements(n *html.Node, atoms ...atom.Atom) []*html.Node {
+	var result []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		for _, a := range atoms {
+			if c.DataAtom == a {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// spanAttr returns the value of the colspan or rowspan attribute of the
+// cell, or def if it is absent or invalid. The value is clamped to max.
+func spanAttr(n *html.Node, attr string, def, max int) int {
+	val, ok := getAttributeValue(attr, n)
+	if !ok {
+		return def
+	}
+	i, e := strconv.Atoi(strings.TrimSpace(val))
+	if e != nil || i < 0 || (i == 0 && attr == "colspan") {
+		return def
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
@@ -0,0 +1,217 @@
+package goquery
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func assertTable(t *testing.T, tbl *Table, header []string, rows, footer [][]string) {
+	if tbl == nil {
+		t.Fatal("expected a table, got nil")
+	}
+	if !reflect.DeepEqual(tbl.Header, header) {
+		t.Errorf("expected header %q, got %q", header, tbl.Header)
+	}
+	if !reflect.DeepEqual(tbl.Rows, rows) {
+		t.Errorf("expected rows %q, got %q", rows, tbl.Rows)
+	}
+	if !reflect.DeepEqual(tbl.Footer, footer) {
+		t.Errorf("expected footer %q, got %q", footer, tbl.Footer)
+	}
+}
+
+func TestNewTable(t *testing.T) {
+	doc := loadString(t, `<table>
+		<thead><tr><th>Name</th><th>Age</th></tr></thead>
+		<tbody>
+			<tr><td>  Alice
+				Smith </td><td>30</td></tr>
+			<tr><td>Bob</td><td>25</td></tr>
+		</tbody>
+		<tfoot><tr><td>Total</td><td>55</td></tr></tfoot>
+	</table>`)
+
+	assertTable(t, NewTable(doc.Find("table")),
+		[]string{"Name", "Age"},
+		[][]string{{"Alice Smith", "30"}, {"Bob", "25"}},
+		[][]string{{"Total", "55"}})
+}
+
+func TestNewTableImplicitHeader(t *testing.T) {
+	doc := loadString(t, `<table>
+		<tr><th>A</th><th>B</th></tr>
+		<tr><td>1</td><td>2</td></tr>
+	</table>`)
+
+	assertTable(t, NewTable(doc.Find("table")),
+		[]string{"A", "B"},
+		[][]string{{"1", "2"}},
+		nil)
+}
+
+func TestNewTableImplicitHeaderEmptyTbody(t *testing.T) {
+	doc := loadString(t, `<table>
+		<tbody></tbody>
+		<tfoot><tr><td>Total</td><td>2</td></tr></tfoot>
+		<tbody>
+			<tr><th>A</th><th>B</th></tr>
+			<tr><td>1</td><td>2</td></tr>
+		</tbody>
+	</table>`)
+
+	assertTable(t, NewTable(doc.Find("table")),
+		[]string{"A", "B"},
+		[][]string{{"1", "2"}},
+		[][]string{{"Total", "2"}})
+}
+
+func TestNewTableNoHeader(t *testing.T) {
+	doc := loadString(t, `<table>
+		<tr><th>Row 1</th><td>1</td></tr>
+		<tr><th>Row 2</th><td>2</td></tr>
+	</table>`)
+
+	assertTable(t, NewTable(doc.Find("table")),
+		nil,
+		[][]string{{"Row 1", "1"}, {"Row 2", "2"}},
+		nil)
+}
+
+func TestNewTableSpans(t *testing.T) {
+	doc := loadString(t, `<table>
+		<tr><td rowspan="2">a</td><td colspan="2">b</td></tr>
+		<tr><td>c</td><td rowspan="0">d</td></tr>
+		<tr><td>e</td><td>f</td></tr>
+		<tr><td colspan="bad">g</td></tr>
+	</table>`)
+
+	assertTable(t, NewTable(doc.Find("table")),
+		nil,
+		[][]string{
+			{"a", "b", "b"},
+			{"a", "c", "d"},
+			{"e", "f", "d"},
+			{"g", "", "d"},
+		},
+		nil)
+}
+
+func TestNewTableRowspanRowGroup(t *testing.T) {
+	// rowspan doesn't extend past the row group
+	doc := loadString(t, `<table>
+		<tbody><tr><td rowspan="3">a</td><td>b</td></tr></tbody>
+		<tbody><tr><td>c</td><td>d</td></tr></tbody>
+	</table>`)
+
+	assertTable(t, NewTable(doc.Find("table")),
+		nil,
+		[][]string{{"a", "b"}, {"c", "d"}},
+		nil)
+}
+
+func TestNewTableMultiRowHeader(t *testing.T) {
+	doc := loadString(t, `<table>
+		<thead>
+			<tr><th rowspan="2">Region</th><th colspan="2">2019</th></tr>
+			<tr><th>Q1</th><th>Q2</th></tr>
+		</thead>
+		<tr><td>North</td><td>1</td><td>2</td></tr>
+	</table>`)
+
+	assertTable(t, NewTable(doc.Find("table")),
+		[]string{"Region", "2019 Q1", "2019 Q2"},
+		[][]string{{"North", "1", "2"}},
+		nil)
+}
+
+func TestNewTableNested(t *testing.T) {
+	doc := loadString(t, `<div><table id="outer">
+		<tr><th>Key</th><th>Value</th></tr>
+		<tr><td>k</td><td><table><tr><td>x</td></tr><tr><td>y</td></tr></table></td></tr>
+	</table></div>`)
+
+	// the first table of the div is used
+	tbl := NewTable(doc.Find("div"))
+	assertTable(t, tbl,
+		[]string{"Key", "Value"},
+		[][]string{{"k", "xy"}},
+		nil)
+
+	assertTable(t, NewTable(doc.Find("#outer td table")),
+		nil,
+		[][]string{{"x"}, {"y"}},
+		nil)
+}
+
+func TestNewTableNone(t *testing.T) {
+	doc := loadString(t, `<p>no table</p>`)
+	if tbl := NewTable(doc.Find("p")); tbl != nil {
+		t.Errorf("expected nil, got %v", tbl)
+	}
+	if tbl := NewTable(doc.Find("table")); tbl != nil {
+		t.Errorf("expected nil, got %v", tbl)
+	}
+}
+
+func TestTableKeys(t *testing.T) {
+	tbl := &Table{
+		Header: []string{"a", "", "a", "b"},
+		Rows:   [][]string{{"1", "2", "3", "4", "5"}},
+	}
+	keys := tbl.Keys()
+	expected := []string{"a", "column2", "a_2", "b", "column5"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %q, got %q", expected, keys)
+	}
+}
+
+func TestTableRecords(t *testing.T) {
+	doc := loadString(t, `<table>
+		<tr><th>Name</th><th>Age</th></tr>
+		<tr><td>Alice</td><td>30</td></tr>
+		<tr><td>Bob</td></tr>
+	</table>`)
+
+	recs := NewTable(doc.Find("table")).Records()
+	expected := []map[string]string{
+		{"Name": "Alice", "Age": "30"},
+		{"Name": "Bob", "Age": ""},
+	}
+	if !reflect.DeepEqual(recs, expected) {
+		t.Errorf("expected %v, got %v", expected, recs)
+	}
+}
+
+func TestTableWriteCSV(t *testing.T) {
+	doc := loadString(t, `<table>
+		<thead><tr><th>Name</th><th>Quote</th></tr></thead>
+		<tr><td>Alice</td><td>"Hi", she said</td></tr>
+		<tfoot><tr><td>ignored</td></tr></tfoot>
+	</table>`)
+
+	var buf bytes.Buffer
+	if e := NewTable(doc.Find("table")).WriteCSV(&buf); e != nil {
+		t.Fatal(e)
+	}
+	expected := "Name,Quote\nAlice,\"\"\"Hi\"\", she said\"\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestTableWriteJSON(t *testing.T) {
+	doc := loadString(t, `<table>
+		<tr><th>Name</th><th>Age</th></tr>
+		<tr><td>Alice</td><td>30</td></tr>
+	</table>`)
+
+	var buf bytes.Buffer
+	if e := NewTable(doc.Find("table")).WriteJSON(&buf); e != nil {
+		t.Fatal(e)
+	}
+	expected := `[{"Age":"30","Name":"Alice"}]` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
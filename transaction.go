@@ -0,0 +1,130 @@
+package goquery
+
+import "errors"
+
+// Errors returned by the methods of Tx.
+var (
+	ErrTxDone           = errors.New("goquery: transaction has already been committed or rolled back")
+	ErrInvalidSavepoint = errors.New("goquery: invalid savepoint")
+)
+
+// Tx is a transaction on a Document, started by Document.Begin. While it
+// is active, the changes made to the document by the Selection methods
+// (insertions, removals and moves of nodes, changes of attributes and of
+// the text of text nodes) are recorded, so that they can be undone by
+// Rollback, or by RollbackTo up to a Savepoint.
+//
+// Only the changes made through the Selections of this Document are
+// recorded, including those made to its nodes while they are not in the
+// document (for example, once removed by Remove). The changes made
+// directly to the html.Node values, or through another Document created
+// on the same nodes, are not. The recorded changes are only referenced by
+// the Document, so a transaction that is never finished does not outlive
+// it. Like the Document, a Tx is not safe for concurrent use.
+type Tx struct {
+	st    *docState
+	start int
+	done  bool
+}
+
+// Savepoint is a position in the changes recorded by a transaction, see
+// Tx.Savepoint.
+type Savepoint struct {
+	tx  *Tx
+	pos int
+}
+
+// Begin starts a transaction on the document. If a transaction is already
+// active on the document, the new transaction is nested in it: committing
+// it keeps its changes in the enclosing transaction, so that they can
+// still be rolled back by it, and finishing the enclosing transaction also
+// finishes the nested one. The changes are recorded until the outermost
+// transaction is committed or rolled back.
+func (d *Document) Begin() *Tx {
+	st := d.docState()
+	tx := &Tx{st: st, start: len(st.log)}
+	st.txs = append(st.txs, tx)
+	return tx
+}
+
+// Commit ends the transaction, keeping its changes. It returns ErrTxDone if
+// the transaction has already been committed or rolled back.
+func (tx *Tx) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.finish()
+	return nil
+}
+
+// Rollback ends the transaction, undoing its changes in reverse order. It
+// returns ErrTxDone if the transaction has already been committed or
+// rolled back.
+func (tx *Tx) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.st.undo(tx.start)
+	tx.finish()
+	return nil
+}
+
+// Savepoint returns the current position in the changes recorded by the
+// transaction, to be used with RollbackTo.
+func (tx *Tx) Savepoint() Savepoint {
+	return Savepoint{tx: tx, pos: len(tx.st.log)}
+}
+
+// RollbackTo undoes the changes made since the savepoint sp was taken, in
+// reverse order, and keeps the transaction active. The transactions nested
+// in it since then are rolled back too. The savepoint remains valid, but
+// those taken after it must not be used anymore. It returns ErrTxDone if
+// the transaction has already been committed or rolled back, and
+// ErrInvalidSavepoint if sp was not taken on this transaction.
+func (tx *Tx) RollbackTo(sp Savepoint) error {
+	if tx.done {
+		return ErrTxDone
+	}
+	if sp.tx != tx || sp.pos < tx.start || sp.pos > len(tx.st.log) {
+		return ErrInvalidSavepoint
+	}
+
+	st := tx.st
+	for i := tx.index() + 1; i < len(st.txs); i++ {
+		if st.txs[i].start >= sp.pos {
+			st.endTxs(i)
+			break
+		}
+	}
+	st.undo(sp.pos)
+	return nil
+}
+
+// finish ends the transaction and the transactions nested in it.
+func (tx *Tx) finish() {
+	tx.st.endTxs(tx.index())
+}
+
+// index returns the index of the transaction in the active transactions of
+// its document.
+func (tx *Tx) index() int {
+	for i, t := range tx.st.txs {
+		if t == tx {
+			return i
+		}
+	}
+	return len(tx.st.txs)
+}
+
+// endTxs ends the active transactions from the index i, and drops the
+// recorded changes if there is none left.
+func (st *docState) endTxs(i int) {
+	for j := i; j < len(st.txs); j++ {
+		st.txs[j].done = true
+		st.txs[j] = nil
+	}
+	st.txs = st.txs[:i]
+	if len(st.txs) == 0 {
+		st.log = nil
+	}
+}
@@ -0,0 +1,124 @@
+package goquery
+
+import (
+	"testing"
+)
+
+func mustHtml(t *testing.T, d *Document) string {
+	h, err := OuterHtml(d.Selection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestTxRollback(t *testing.T) {
+	doc := Doc2Clone()
+	before := mustHtml(t, doc)
+
+	tx := doc.Begin()
+	doc.Find("#main").SetAttr("id", "x").AddClass("a b").SetCss("color", "red")
+	doc.Find("#foot").RemoveAttr("class").Remove()
+	doc.Find("#nf1").ReplaceWithHtml(`<p>new</p>`)
+	doc.Find("#n1, #n2").WrapHtml(`<section><div></div></section>`)
+	doc.Find("#nf2").AppendSelection(doc.Find("#nf3"))
+	doc.Find("#nf4").SetText("text").Contents().SetProp("textContent", "data")
+	doc.Find("#nf5").Empty()
+	DefaultSanitizePolicy().Sanitize(doc.Find("body"))
+	if mustHtml(t, doc) == before {
+		t.Fatal("expected the document to be changed")
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if after := mustHtml(t, doc); after != before {
+		t.Errorf("expected the document to be restored, got\n%s\nwant\n%s", after, before)
+	}
+	if err := tx.Rollback(); err != ErrTxDone {
+		t.Errorf("expected ErrTxDone, got %v", err)
+	}
+	if err := tx.Commit(); err != ErrTxDone {
+		t.Errorf("expected ErrTxDone, got %v", err)
+	}
+}
+
+func TestTxCommit(t *testing.T) {
+	doc := Doc2Clone()
+
+	tx := doc.Begin()
+	doc.Find("#main").SetAttr("id", "x")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, doc.Find("#x").Nodes, 1)
+	if err := tx.Rollback(); err != ErrTxDone {
+		t.Errorf("expected ErrTxDone, got %v", err)
+	}
+	if n := len(doc.state.log); n != 0 {
+		t.Errorf("expected no recorded change, got %d", n)
+	}
+}
+
+func TestTxSavepoint(t *testing.T) {
+	doc := Doc2Clone()
+	tx := doc.Begin()
+	defer tx.Commit()
+
+	doc.Find("#main").SetAttr("data-step", "1")
+	sp := tx.Savepoint()
+	at := mustHtml(t, doc)
+	doc.Find("#main").SetAttr("data-step", "2").Remove()
+
+	if err := tx.RollbackTo(sp); err != nil {
+		t.Fatal(err)
+	}
+	if after := mustHtml(t, doc); after != at {
+		t.Errorf("expected the document at the savepoint, got\n%s", after)
+	}
+	if v, _ := doc.Find("#main").Attr("data-step"); v != "1" {
+		t.Errorf("expected data-step 1, got %q", v)
+	}
+
+	other := Doc2Clone().Begin()
+	defer other.Rollback()
+	if err := tx.RollbackTo(other.Savepoint()); err != ErrInvalidSavepoint {
+		t.Errorf("expected ErrInvalidSavepoint, got %v", err)
+	}
+}
+
+func TestTxNested(t *testing.T) {
+	doc := Doc2Clone()
+	before := mustHtml(t, doc)
+
+	outer := doc.Begin()
+	doc.Find("#main").SetAttr("a", "1")
+	inner := doc.Begin()
+	doc.Find("#main").SetAttr("b", "2")
+	if err := inner.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	inner = doc.Begin()
+	doc.Find("#main").SetAttr("c", "3")
+	if err := inner.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Find("#main").Attr("c"); ok {
+		t.Error("expected the inner transaction to be rolled back")
+	}
+	if _, ok := doc.Find("#main").Attr("b"); !ok {
+		t.Error("expected the committed inner transaction to be kept")
+	}
+
+	inner = doc.Begin()
+	if err := outer.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := inner.Commit(); err != ErrTxDone {
+		t.Errorf("expected the nested transaction to be done, got %v", err)
+	}
+	if after := mustHtml(t, doc); after != before {
+		t.Errorf("expected the document to be restored, got\n%s", after)
+	}
+}
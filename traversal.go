@@ -1,6 +1,10 @@
 package goquery
 
-import "golang.org/x/net/html"
+import (
+	"github.com/andybalholm/cascadia"
+
+	"golang.org/x/net/html"
+)
 
 type siblingType int
 
@@ -21,7 +25,7 @@ const (
 // elements, filtered by a selector. It returns a new Selection object
 // containing these matched elements.
 func (s *Selection) Find(selector string) *Selection {
-	return pushStack(s, findWithMatcher(s.Nodes, compileMatcher(selector)))
+	return pushStack(s, findWithSelector(s.document, s.Nodes, selector))
 }
 
 // FindMatcher gets the descendants of each element in the current set of matched
@@ -31,6 +35,41 @@ func (s *Selection) FindMatcher(m Matcher) *Selection {
 	return pushStack(s, findWithMatcher(s.Nodes, m))
 }
 
+// FindE is like Find, except that it returns an error (a *SelectorError)
+// instead of an empty Selection if the selector string is invalid.
+func (s *Selection) FindE(selector string) (*Selection, error) {
+	m, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.FindMatcher(m), nil
+}
+
+// FindFirst gets the first descendant of the elements in the current set of
+// matched elements that matches the selector. It returns the same element as
+// Find(selector).First(), but stops walking the document tree as soon as a
+// match is found. It returns a new Selection object containing the matched
+// element, or an empty Selection object if there is none.
+func (s *Selection) FindFirst(selector string) *Selection {
+	return s.FindFirstMatcher(compileMatcher(selector))
+}
+
+// FindFirstMatcher gets the first descendant of the elements in the current
+// set of matched elements that matches the matcher. It returns the same
+// element as FindMatcher(m).First(), using the MatchAll method of m. For the
+// matchers compiled by Compile, whose MatchAll returns the nodes accepted by
+// Match, it stops walking the document tree as soon as a match is found. It
+// returns a new Selection object containing the matched element, or an
+// empty Selection object if there is none.
+func (s *Selection) FindFirstMatcher(m Matcher) *Selection {
+	for _, n := range s.Nodes {
+		if c := findFirstWithMatcher(n, m); c != nil {
+			return pushStack(s, []*html.Node{c})
+		}
+	}
+	return pushStack(s, nil)
+}
+
 // FindSelection gets the descendants of each element in the current
 // Selection, filtered by a Selection. It returns a new Selection object
 // containing these matched elements.
@@ -125,6 +164,16 @@ func (s *Selection) Closest(selector string) *Selection {
 	return s.ClosestMatcher(cs)
 }
 
+// ClosestE is like Closest, except that it returns an error (a *SelectorError)
+// instead of an empty Selection if the selector string is invalid.
+func (s *Selection) ClosestE(selector string) (*Selection, error) {
+	m, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.ClosestMatcher(m), nil
+}
+
 // ClosestMatcher gets the first element that matches the matcher by testing the
 // element itself and traversing up through its ancestors in the DOM tree.
 func (s *Selection) ClosestMatcher(m Matcher) *Selection {
@@ -551,6 +600,24 @@ func findWithMatcher(nodes []*html.Node, m Matcher) []*html.Node {
 	})
 }
 
+// Internal implementation of FindFirst that returns the first descendant of
+// n that matches m, in depth-first order, or nil.
+func findFirstWithMatcher(n *html.Node, m Matcher) *html.Node {
+	if sel, ok := m.(cascadia.Selector); ok {
+		return cascadia.Query(n, sel)
+	}
+	// like findWithMatcher, for the matchers whose Match and MatchAll may
+	// not agree
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			if result := m.MatchAll(c); len(result) > 0 {
+				return result[0]
+			}
+		}
+	}
+	return nil
+}
+
 // Internal implementation to get all parent nodes, stopping at the specified
 // node (or nil if no stop).
 func getParentsNodes(nodes []*html.Node, stopm Matcher, stopNodes []*html.Node) []*html.Node {
@@ -3,6 +3,8 @@ package goquery
 import (
 	"strings"
 	"testing"
+
+	"golang.org/x/net/html"
 )
 
 func TestFind(t *testing.T) {
@@ -791,3 +793,78 @@ func TestIssue26(t *testing.T) {
 		assertLength(t, sel.Nodes, c.l)
 	}
 }
+
+func TestFindE(t *testing.T) {
+	sel, err := Doc().FindE("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, sel.Nodes, 4)
+
+	if _, err := Doc().FindE("a:"); err == nil {
+		t.Error("expected an error for a truncated selector")
+	}
+	assertLength(t, Doc().Find("a:").Nodes, 0)
+	if _, err := Doc().FindE(":+ ^"); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestClosestE(t *testing.T) {
+	sel, err := Doc().Find(".row-fluid").ClosestE("div.pvk-content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLength(t, sel.Nodes, 2)
+
+	if _, err := Doc().Find(".row-fluid").ClosestE(""); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestFindFirst(t *testing.T) {
+	sel := Doc().Find(".pvk-content")
+	sel2 := sel.FindFirst("p")
+	assertLength(t, sel2.Nodes, 1)
+	if sel2.Get(0) != sel.Find("p").Get(0) {
+		t.Errorf("expected the first p, got %+v", sel2.Get(0))
+	}
+	assertEqual(t, sel2.End(), sel)
+}
+
+func TestFindFirstNotInFirstNode(t *testing.T) {
+	sel := Doc2().Find("#main, #foot")
+	sel2 := sel.FindFirst(".odder")
+	assertLength(t, sel2.Nodes, 1)
+	assertSelectionIs(t, sel2, "#nf5")
+}
+
+func TestFindFirstNone(t *testing.T) {
+	assertLength(t, Doc().FindFirst("zzzz").Nodes, 0)
+}
+
+func TestFindFirstInvalid(t *testing.T) {
+	assertLength(t, Doc().FindFirst("~").Nodes, 0)
+}
+
+func TestFindFirstMatcher(t *testing.T) {
+	sel := Doc2().FindFirstMatcher(compileMatcher(".row"))
+	assertSelectionIs(t, sel, "#n1")
+}
+
+// matchAllOnly is a Matcher whose Match never matches, while MatchAll
+// returns the matches of its Matcher.
+type matchAllOnly struct {
+	Matcher
+}
+
+func (matchAllOnly) Match(*html.Node) bool { return false }
+
+func TestFindFirstMatcherMatchAll(t *testing.T) {
+	m := matchAllOnly{compileMatcher(".row")}
+	sel := Doc2().FindFirstMatcher(m)
+	assertLength(t, sel.Nodes, 1)
+	if sel.Get(0) != Doc2().FindMatcher(m).First().Get(0) {
+		t.Errorf("expected the first node of FindMatcher, got %+v", sel.Get(0))
+	}
+}
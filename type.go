@@ -2,6 +2,7 @@ package goquery
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -18,8 +19,25 @@ import (
 // document node to manipulate, and can make selections on this document.
 type Document struct {
 	*Selection
-	Url      *url.URL
+	Url *url.URL
+
+	// Encoding is the name of the character encoding that was detected and
+	// converted to UTF-8 when creating the document with
+	// NewDocumentFromReaderWithCharset or the WithCharset option (e.g.
+	// "utf-8", "windows-1252" or "shift_jis"). It is empty if no detection
+	// was made.
+	Encoding string
+
 	rootNode *html.Node
+
+	// the state of the transactions and observers, see Begin and Observe
+	state *docState
+
+	// the index of the elements, see BuildIndex
+	index *docIndex
+
+	// the pre-order numbering of the nodes, see CompareDocumentPosition
+	order *orderCache
 }
 
 // NewDocumentFromNode is a Document constructor that takes a root html Node
@@ -32,9 +50,9 @@ func NewDocumentFromNode(root *html.Node) *Document {
 // It loads the specified document, parses it, and stores the root Document
 // node, ready to be manipulated.
 //
-// Deprecated: Use the net/http standard library package to make the request
-// and validate the response before calling goquery.NewDocumentFromReader
-// with the response's body.
+// Deprecated: Use a Loader, or the net/http standard library package to make
+// the request and validate the response before calling
+// goquery.NewDocumentFromReader with the response's body.
 func NewDocument(url string) (*Document, error) {
 	// Load the URL
 	res, e := http.Get(url)
@@ -57,11 +75,44 @@ func NewDocumentFromReader(r io.Reader) (*Document, error) {
 	return newDocument(root, nil), nil
 }
 
+// NewDocumentFromReaderWithCharset returns a Document from an io.Reader whose
+// content may not be UTF-8 encoded. The encoding is sniffed following the
+// WHATWG encoding sniffing algorithm: a byte order mark takes precedence,
+// then the charset parameter of contentType (typically the value of the
+// Content-Type header of the HTTP response, it may be empty), then the
+// <meta charset> or <meta http-equiv="Content-Type"> element in the first
+// 1024 bytes of the document. Failing that, the content is assumed to be
+// UTF-8 if it is valid UTF-8, windows-1252 otherwise. The content is then
+// converted to UTF-8 before being parsed, and the name of the encoding is
+// recorded in the Document's Encoding field.
+//
+// As for NewDocumentFromReader, the reader is never closed by this call.
+func NewDocumentFromReaderWithCharset(r io.Reader, contentType string) (*Document, error) {
+	return NewDocumentFromReaderWithOptions(r, WithCharset(contentType))
+}
+
+// NewDocumentFromReaderWithOptions returns a Document from an io.Reader,
+// configured by the provided options (see the With... functions that
+// return an Option). Without option, it behaves like NewDocumentFromReader.
+//
+// As for NewDocumentFromReader, the reader is never closed by this call.
+func NewDocumentFromReaderWithOptions(r io.Reader, opts ...Option) (*Document, error) {
+	o := newOptions(opts)
+	root, enc, e := o.parse(r)
+	if e != nil {
+		return nil, e
+	}
+	d := newDocument(root, o.url)
+	d.Encoding = enc
+	return d, nil
+}
+
 // NewDocumentFromResponse is another Document constructor that takes an http response as argument.
 // It loads the specified response's document, parses it, and stores the root Document
 // node, ready to be manipulated. The response's body is closed on return.
 //
-// Deprecated: Use goquery.NewDocumentFromReader with the response's body.
+// Deprecated: Use a Loader, or goquery.NewDocumentFromReader with the
+// response's body.
 func NewDocumentFromResponse(res *http.Response) (*Document, error) {
 	if res == nil {
 		return nil, errors.New("Response is nil")
@@ -83,13 +134,15 @@ func NewDocumentFromResponse(res *http.Response) (*Document, error) {
 
 // CloneDocument creates a deep-clone of a document.
 func CloneDocument(doc *Document) *Document {
-	return newDocument(cloneNode(doc.rootNode), doc.Url)
+	d := newDocument(cloneNode(doc.rootNode), doc.Url)
+	d.Encoding = doc.Encoding
+	return d
 }
 
 // Private constructor, make sure all fields are correctly filled.
 func newDocument(root *html.Node, url *url.URL) *Document {
 	// Create and fill the document
-	d := &Document{nil, url, root}
+	d := &Document{Url: url, rootNode: root, order: &orderCache{}}
 	d.Selection = newSingleSelection(root, d)
 	return d
 }
@@ -122,15 +175,99 @@ type Matcher interface {
 	Filter([]*html.Node) []*html.Node
 }
 
+// SelectorError is the error returned by Compile when the selector string
+// is invalid. Offset is the byte offset in Selector at which the invalid
+// part starts, as far as it can be determined from the longest valid prefix
+// of the selector.
+type SelectorError struct {
+	Selector string
+	Offset   int
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("goquery: invalid selector %q at offset %d: %v", e.Selector, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying cascadia parse error.
+func (e *SelectorError) Unwrap() error {
+	return e.Err
+}
+
+// Compile compiles the selector string s and returns the corresponding
+// Matcher. Unlike the methods that take a selector string argument, which
+// silently use a Matcher that never matches when the selector is invalid,
+// it returns a *SelectorError describing the parse failure.
+func Compile(s string) (Matcher, error) {
+	if m, ok := selectorCache.get(s); ok {
+		if _, invalid := m.(invalidMatcher); !invalid {
+			return m, nil
+		}
+	}
+	cs, err := compileSelector(s)
+	if err != nil {
+		return nil, &SelectorError{Selector: s, Offset: invalidSelectorOffset(s), Err: err}
+	}
+	selectorCache.add(s, cs)
+	return cs, nil
+}
+
+// MustCompile is like Compile but panics if the selector string is invalid.
+// It is meant to be used to initialize package-level Matchers.
+func MustCompile(s string) Matcher {
+	m, err := Compile(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// invalidSelectorOffset returns the length of the longest prefix of the
+// invalid selector string s that compiles successfully, which is where the
+// parse error starts. Cascadia does not expose the position of its errors,
+// and this is only called on the error path, so the quadratic cost is fine.
+func invalidSelectorOffset(s string) int {
+	for i := len(s) - 1; i > 0; i-- {
+		if isValidSelector(s[:i]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// isValidSelector returns true if s compiles.
+func isValidSelector(s string) bool {
+	_, err := compileSelector(s)
+	return err == nil
+}
+
+// compileSelector is like cascadia.Compile, but returns an error for the
+// truncated selectors that make cascadia panic (e.g. a lone ":").
+func compileSelector(s string) (sel cascadia.Selector, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			sel, err = nil, fmt.Errorf("failed to parse selector: %v", e)
+		}
+	}()
+	return cascadia.Compile(s)
+}
+
 // compileMatcher compiles the selector string s and returns
 // the corresponding Matcher. If s is an invalid selector string,
-// it returns a Matcher that fails all matches.
+// it returns a Matcher that fails all matches. Compiled selectors
+// are cached, see SetSelectorCacheSize.
 func compileMatcher(s string) Matcher {
-	cs, err := cascadia.Compile(s)
-	if err != nil {
-		return invalidMatcher{}
+	if m, ok := selectorCache.get(s); ok {
+		return m
+	}
+
+	var m Matcher = invalidMatcher{}
+	if cs, err := compileSelector(s); err == nil {
+		m = cs
 	}
-	return cs
+	selectorCache.add(s, m)
+	return m
 }
 
 // invalidMatcher is a Matcher that always fails to match.
@@ -208,3 +208,153 @@ func TestIssue103(t *testing.T) {
 	}
 	t.Log(text)
 }
+
+func TestCompile(t *testing.T) {
+	m, err := Compile("div.row-fluid")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertLength(t, Doc().FindMatcher(m).Nodes, 9)
+}
+
+func TestCompileInvalid(t *testing.T) {
+	cases := []struct {
+		sel    string
+		offset int
+	}{
+		{"", 0},
+		{"~", 0},
+		{"a[href", 1},
+		{"div > ", 4},
+		{"div p:nth-child(2", 5},
+		{":", 0},
+		{"a:", 1},
+	}
+	for i, c := range cases {
+		m, err := Compile(c.sel)
+		if m != nil {
+			t.Errorf("[%d] %q: expected nil Matcher, got %v", i, c.sel, m)
+		}
+		se, ok := err.(*SelectorError)
+		if !ok {
+			t.Errorf("[%d] %q: expected *SelectorError, got %T", i, c.sel, err)
+			continue
+		}
+		if se.Selector != c.sel {
+			t.Errorf("[%d] %q: expected selector in error, got %q", i, c.sel, se.Selector)
+		}
+		if se.Offset != c.offset {
+			t.Errorf("[%d] %q: expected offset %d, got %d", i, c.sel, c.offset, se.Offset)
+		}
+		if se.Unwrap() == nil {
+			t.Errorf("[%d] %q: expected wrapped cascadia error", i, c.sel)
+		}
+	}
+}
+
+func TestMustCompileInvalid(t *testing.T) {
+	defer assertPanic(t)
+	MustCompile("~")
+}
+
+func TestNewDocumentFromReaderWithCharset(t *testing.T) {
+	cases := []struct {
+		src  []byte
+		ct   string
+		enc  string
+		text string
+	}{
+		0: {
+			// plain utf-8, no declaration
+			src:  []byte("<html><title>Café</title></html>"),
+			enc:  "utf-8",
+			text: "Café",
+		},
+		1: {
+			// windows-1252 bytes, no declaration
+			src:  []byte("<html><title>Caf\xe9</title></html>"),
+			enc:  "windows-1252",
+			text: "Café",
+		},
+		2: {
+			// Content-Type header
+			src:  []byte("<html><title>Caf\xe9</title></html>"),
+			ct:   "text/html; charset=ISO-8859-1",
+			enc:  "windows-1252",
+			text: "Café",
+		},
+		3: {
+			// meta charset
+			src:  []byte(`<html><head><meta charset="shift_jis"><title>` + "\x93\xfa\x96\x7b" + `</title></head></html>`),
+			enc:  "shift_jis",
+			text: "日本",
+		},
+		4: {
+			// meta http-equiv
+			src:  []byte(`<html><head><meta http-equiv="Content-Type" content="text/html; charset=koi8-r"><title>` + "\xf0\xd2\xc9\xd7\xc5\xd4" + `</title></head></html>`),
+			enc:  "koi8-r",
+			text: "Привет",
+		},
+		5: {
+			// Content-Type header takes precedence over meta
+			src:  []byte(`<html><head><meta charset="shift_jis"><title>Caf` + "\xe9" + `</title></head></html>`),
+			ct:   "text/html; charset=windows-1252",
+			enc:  "windows-1252",
+			text: "Café",
+		},
+		6: {
+			// utf-8 byte order mark takes precedence over everything
+			src:  []byte("\xef\xbb\xbf<html><head><meta charset=\"windows-1252\"><title>Café</title></head></html>"),
+			ct:   "text/html; charset=windows-1252",
+			enc:  "utf-8",
+			text: "Café",
+		},
+		7: {
+			// utf-16le byte order mark
+			src:  []byte("\xff\xfe<\x00p\x00>\x00\xe9\x00"),
+			enc:  "utf-16le",
+			text: "é",
+		},
+	}
+
+	for i, c := range cases {
+		d, err := NewDocumentFromReaderWithCharset(bytes.NewReader(c.src), c.ct)
+		if err != nil {
+			t.Errorf("[%d] - expected no error, got %s", i, err)
+			continue
+		}
+		if d.Encoding != c.enc {
+			t.Errorf("[%d] - expected encoding %q, got %q", i, c.enc, d.Encoding)
+		}
+		sel := "title"
+		if d.Find(sel).Length() == 0 {
+			sel = "p"
+		}
+		if got := d.Find(sel).Text(); got != c.text {
+			t.Errorf("[%d] - expected text %q, got %q", i, c.text, got)
+		}
+		if h, _ := d.Find("html").Html(); strings.ContainsRune(h, '\uFEFF') {
+			t.Errorf("[%d] - expected the byte order mark to be removed", i)
+		}
+	}
+}
+
+func TestNewDocumentFromReaderWithCharsetLarge(t *testing.T) {
+	// the declaration must be found in the first 1024 bytes, the rest of
+	// the document must be transcoded too.
+	src := `<html><head><meta charset="windows-1252"></head><body>` +
+		strings.Repeat("<p>x</p>", 500) + "<h1>Caf\xe9</h1></body></html>"
+	d, err := NewDocumentFromReaderWithCharset(strings.NewReader(src), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Encoding != "windows-1252" {
+		t.Errorf("expected encoding windows-1252, got %q", d.Encoding)
+	}
+	if got := d.Find("h1").Text(); got != "Café" {
+		t.Errorf("expected Café, got %q", got)
+	}
+	if CloneDocument(d).Encoding != d.Encoding {
+		t.Error("expected the clone to keep the encoding")
+	}
+}
@@ -0,0 +1,331 @@
+package goquery
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal
+// themselves from a Selection. When Unmarshal encounters a field whose
+// type (or a pointer to it) implements Unmarshaler, it calls its
+// UnmarshalSelection method with the Selection matched by the field's tag.
+type Unmarshaler interface {
+	UnmarshalSelection(*Selection) error
+}
+
+// UnmarshalError is the error returned by Unmarshal when a value cannot be
+// stored in a field. Field is the path to the field from the value passed
+// to Unmarshal (e.g. "Items[2].Price").
+type UnmarshalError struct {
+	Field    string
+	Selector string
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("goquery: cannot unmarshal field %s (selector %q): %v", e.Field, e.Selector, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// Unmarshal fills the struct pointed to by v with values extracted from the
+// Selection, based on the goquery struct tags of its fields. Fields without
+// such a tag (or with the tag "-") are ignored. The tag holds a selector,
+// optionally followed by comma-separated options:
+//
+//     Title string    `goquery:"h1.title"`
+//     Link  string    `goquery:"a,attr=href"`
+//     Price float64   `goquery:".price,text,trim"`
+//     Date  time.Time `goquery:"time,attr=datetime,layout=2006-01-02"`
+//
+// The selector is applied to the Selection with Find. If it is empty, the
+// Selection itself is used. If the selector doesn't match anything, the
+// field is left unchanged. The supported options are:
+//
+//     text         the value is the text of the element (the default)
+//     html         the value is the inner HTML of the element
+//     attr=name    the value is the element's attribute name
+//     abs          with attr, the value is resolved as a URL (see AbsAttr)
+//     trim         leading and trailing whitespace is removed from the value
+//     layout=l     the layout used to parse time.Time values (the default
+//                  is time.RFC3339)
+//
+// Since options follow the selector after a comma, the selector may be a
+// group of selectors only if no word of the group is a valid option.
+//
+// The type of the field determines how the value is stored:
+//
+//     - string, integers, floats and bools are parsed from the value of the
+//       first matched element, numbers and bools after trimming whitespace;
+//     - time.Time is parsed with the layout option;
+//     - types implementing encoding.TextUnmarshaler receive the value;
+//     - structs are filled recursively with the first matched element as
+//       Selection;
+//     - slices receive one element per matched element;
+//     - pointers are allocated when the selector matches;
+//     - types implementing Unmarshaler receive the matched Selection (all the
+//       matched elements, unlike the other types).
+//
+// Invalid selectors and values that cannot be parsed return an
+// *UnmarshalError, as do the recursive struct types that would be filled
+// from the same element forever, through fields with an empty selector.
+func Unmarshal(sel *Selection, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("goquery: Unmarshal requires a non-nil pointer")
+	}
+
+	// a top-level struct is filled from the whole Selection, not just its
+	// first element as for nested structs.
+	if v := rv.Elem(); isPlainStruct(v) {
+		return unmarshalStruct(sel, v, "", make(map[filledStruct]bool))
+	}
+	return unmarshalValue(sel, rv.Elem(), unmarshalTag{}, "", make(map[filledStruct]bool))
+}
+
+// filledStruct identifies a struct being filled from the first node of a
+// Selection. A recursive type whose fields have an empty selector would be
+// filled from the same node forever, this is detected with the structs
+// being filled.
+type filledStruct struct {
+	typ  reflect.Type
+	node *html.Node
+}
+
+var errRecursiveStruct = errors.New("recursive struct filled from the same element, the selector must not be empty")
+
+// isPlainStruct returns true if v is a struct that must be filled field by
+// field, as opposed to a struct that unmarshals itself.
+func isPlainStruct(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct || v.Type() == timeType {
+		return false
+	}
+	pt := reflect.PtrTo(v.Type())
+	return !pt.Implements(unmarshalerType) && !pt.Implements(textUnmarshalerType)
+}
+
+// unmarshalTag holds the parsed goquery struct tag of a field.
+type unmarshalTag struct {
+	selector string
+	html     bool
+	attr     string
+	abs      bool
+	trim     bool
+	layout   string
+}
+
+func parseUnmarshalTag(tag string) unmarshalTag {
+	var t unmarshalTag
+
+	parts := strings.Split(tag, ",")
+	i := len(parts)
+	// options are at the end, the rest is the selector
+	for ; i > 1; i-- {
+		opt := strings.TrimSpace(parts[i-1])
+		switch {
+		case opt == "text":
+		case opt == "html":
+			t.html = true
+		case opt == "abs":
+			t.abs = true
+		case opt == "trim":
+			t.trim = true
+		case strings.HasPrefix(opt, "attr="):
+			t.attr = strings.TrimPrefix(opt, "attr=")
+		case strings.HasPrefix(opt, "layout="):
+			t.layout = strings.TrimPrefix(opt, "layout=")
+		default:
+			t.selector = strings.TrimSpace(strings.Join(parts[:i], ","))
+			return t
+		}
+	}
+	t.selector = strings.TrimSpace(strings.Join(parts[:i], ","))
+	return t
+}
+
+// value extracts the string value of the first element of sel according to
+// the tag.
+func (t unmarshalTag) value(sel *Selection) (string, error) {
+	var val string
+	switch {
+	case t.attr != "" && t.abs:
+		val, _ = sel.AbsAttr(t.attr)
+	case t.attr != "":
+		val, _ = sel.Attr(t.attr)
+	case t.html:
+		var e error
+		if val, e = sel.Html(); e != nil {
+			return "", e
+		}
+	default:
+		val = sel.Text()
+	}
+	if t.trim {
+		val = strings.TrimSpace(val)
+	}
+	return val, nil
+}
+
+// unmarshalValue stores in v the value extracted from sel. The path is the
+// path to v, used in errors, and filling holds the structs being filled.
+func unmarshalValue(sel *Selection, v reflect.Value, tag unmarshalTag, path string, filling map[filledStruct]bool) error {
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		if e := v.Addr().Interface().(Unmarshaler).UnmarshalSelection(sel); e != nil {
+			return &UnmarshalError{Field: path, Selector: tag.selector, Err: e}
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(sel, v.Elem(), tag, path, filling)
+
+	case reflect.Struct:
+		if isPlainStruct(v) {
+			return unmarshalStruct(sel.First(), v, path, filling)
+		}
+
+	case reflect.Slice:
+		if !reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+			return unmarshalSlice(sel, v, tag, path, filling)
+		}
+	}
+
+	val, e := tag.value(sel.First())
+	if e != nil {
+		return &UnmarshalError{Field: path, Selector: tag.selector, Err: e}
+	}
+	if e := setScalar(v, val, tag); e != nil {
+		return &UnmarshalError{Field: path, Selector: tag.selector, Err: e}
+	}
+	return nil
+}
+
+// unmarshalStruct fills the tagged fields of the struct v.
+func unmarshalStruct(sel *Selection, v reflect.Value, path string, filling map[filledStruct]bool) error {
+	t := v.Type()
+	key := filledStruct{typ: t}
+	if len(sel.Nodes) > 0 {
+		key.node = sel.Nodes[0]
+	}
+	if filling[key] {
+		return &UnmarshalError{Field: path, Err: errRecursiveStruct}
+	}
+	filling[key] = true
+	defer delete(filling, key)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		raw, ok := f.Tag.Lookup("goquery")
+		if !ok || raw == "-" || f.PkgPath != "" {
+			// untagged, ignored or unexported field
+			continue
+		}
+
+		tag := parseUnmarshalTag(raw)
+		fpath := f.Name
+		if path != "" {
+			fpath = path + "." + f.Name
+		}
+
+		sub := sel
+		if tag.selector != "" {
+			m, e := Compile(tag.selector)
+			if e != nil {
+				return &UnmarshalError{Field: fpath, Selector: tag.selector, Err: e}
+			}
+			sub = sel.FindMatcher(m)
+		}
+		if sub.Length() == 0 {
+			continue
+		}
+		if e := unmarshalValue(sub, v.Field(i), tag, fpath, filling); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// unmarshalSlice sets the slice v to one element per node of sel.
+func unmarshalSlice(sel *Selection, v reflect.Value, tag unmarshalTag, path string, filling map[filledStruct]bool) error {
+	s := reflect.MakeSlice(v.Type(), sel.Length(), sel.Length())
+	for i, n := range sel.Nodes {
+		if e := unmarshalValue(newSingleSelection(n, sel.document), s.Index(i), tag,
+			fmt.Sprintf("%s[%d]", path, i), filling); e != nil {
+			return e
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+// setScalar parses val and stores it in v based on v's type.
+func setScalar(v reflect.Value, val string, tag unmarshalTag) error {
+	if v.Type() == timeType {
+		layout := tag.layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, e := time.Parse(layout, strings.TrimSpace(val))
+		if e != nil {
+			return e
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Bool:
+		b, e := strconv.ParseBool(strings.TrimSpace(val))
+		if e != nil {
+			return e
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, e := strconv.ParseInt(strings.TrimSpace(val), 10, v.Type().Bits())
+		if e != nil {
+			return e
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, e := strconv.ParseUint(strings.TrimSpace(val), 10, v.Type().Bits())
+		if e != nil {
+			return e
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, e := strconv.ParseFloat(strings.TrimSpace(val), v.Type().Bits())
+		if e != nil {
+			return e
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
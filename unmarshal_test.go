@@ -0,0 +1,254 @@
+package goquery
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const unmarshalPage = `<html><body>
+<h1 class="title"> The Title </h1>
+<a id="home" href="/index.html">Home</a>
+<div class="meta">
+	<span class="count">42</span>
+	<span class="ratio"> 0.75 </span>
+	<span class="ok">true</span>
+	<time datetime="2020-10-08">Oct 8</time>
+	<span class="big">300</span>
+</div>
+<ul>
+	<li class="item" data-id="1"><span class="name">a</span><span class="price">1.5</span></li>
+	<li class="item" data-id="2"><span class="name">b</span><span class="price">2.5</span></li>
+	<li class="item" data-id="3"><span class="name">c</span><span class="price">3.5</span></li>
+</ul>
+<p class="content">Some <b>bold</b> text</p>
+<span class="upper">shout</span>
+</body></html>`
+
+type unmarshalItem struct {
+	ID    int     `goquery:",attr=data-id"`
+	Name  string  `goquery:".name"`
+	Price float64 `goquery:".price"`
+}
+
+type upperString string
+
+func (u *upperString) UnmarshalSelection(sel *Selection) error {
+	*u = upperString(strings.ToUpper(sel.Text()))
+	return nil
+}
+
+type listCounter int
+
+func (c *listCounter) UnmarshalText(b []byte) error {
+	*c = listCounter(len(b))
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	var v struct {
+		Title    string          `goquery:"h1.title"`
+		Trimmed  string          `goquery:"h1.title,text,trim"`
+		Link     string          `goquery:"#home,attr=href"`
+		Count    int             `goquery:".meta .count"`
+		Ratio    float32         `goquery:".meta .ratio"`
+		OK       bool            `goquery:".meta .ok"`
+		Date     time.Time       `goquery:"time,attr=datetime,layout=2006-01-02"`
+		Big      *uint16         `goquery:".meta .big"`
+		Missing  *string         `goquery:".zzz"`
+		Default  string          `goquery:".zzz"`
+		Content  string          `goquery:"p.content,html"`
+		Upper    upperString     `goquery:".upper"`
+		Len      listCounter     `goquery:".upper"`
+		Items    []unmarshalItem `goquery:"li.item"`
+		Names    []string        `goquery:"li .name"`
+		First    unmarshalItem   `goquery:"li.item"`
+		PFirst   *unmarshalItem  `goquery:"li.item"`
+		Ignored  string          `goquery:"-"`
+		Untagged string
+		private  string          `goquery:"h1"`
+	}
+	v.Default = "default"
+
+	if err := Unmarshal(loadString(t, unmarshalPage).Selection, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Title != " The Title " {
+		t.Errorf("Title: got %q", v.Title)
+	}
+	if v.Trimmed != "The Title" {
+		t.Errorf("Trimmed: got %q", v.Trimmed)
+	}
+	if v.Link != "/index.html" {
+		t.Errorf("Link: got %q", v.Link)
+	}
+	if v.Count != 42 {
+		t.Errorf("Count: got %d", v.Count)
+	}
+	if v.Ratio != 0.75 {
+		t.Errorf("Ratio: got %f", v.Ratio)
+	}
+	if !v.OK {
+		t.Errorf("OK: got %t", v.OK)
+	}
+	if want := time.Date(2020, 10, 8, 0, 0, 0, 0, time.UTC); !v.Date.Equal(want) {
+		t.Errorf("Date: got %s", v.Date)
+	}
+	if v.Big == nil || *v.Big != 300 {
+		t.Errorf("Big: got %v", v.Big)
+	}
+	if v.Missing != nil {
+		t.Errorf("Missing: expected nil, got %q", *v.Missing)
+	}
+	if v.Default != "default" {
+		t.Errorf("Default: expected unchanged value, got %q", v.Default)
+	}
+	if v.Content != "Some <b>bold</b> text" {
+		t.Errorf("Content: got %q", v.Content)
+	}
+	if v.Upper != "SHOUT" {
+		t.Errorf("Upper: got %q", v.Upper)
+	}
+	if v.Len != 5 {
+		t.Errorf("Len: got %d", v.Len)
+	}
+	if len(v.Items) != 3 {
+		t.Fatalf("Items: expected 3 items, got %d", len(v.Items))
+	}
+	for i, it := range v.Items {
+		want := unmarshalItem{ID: i + 1, Name: string(rune('a' + i)), Price: float64(i) + 1.5}
+		if it != want {
+			t.Errorf("Items[%d]: expected %+v, got %+v", i, want, it)
+		}
+	}
+	if strings.Join(v.Names, "") != "abc" {
+		t.Errorf("Names: got %v", v.Names)
+	}
+	if v.First != v.Items[0] {
+		t.Errorf("First: got %+v", v.First)
+	}
+	if v.PFirst == nil || *v.PFirst != v.Items[0] {
+		t.Errorf("PFirst: got %+v", v.PFirst)
+	}
+	if v.Ignored != "" || v.Untagged != "" || v.private != "" {
+		t.Error("expected ignored fields to be left unchanged")
+	}
+}
+
+func TestUnmarshalAbs(t *testing.T) {
+	d := loadStringURL(t, unmarshalPage, "https://example.com/a/b")
+	var v struct {
+		Link string `goquery:"#home,attr=href,abs"`
+	}
+	if err := Unmarshal(d.Selection, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Link != "https://example.com/index.html" {
+		t.Errorf("Link: got %q", v.Link)
+	}
+}
+
+func TestUnmarshalSelectorGroup(t *testing.T) {
+	var v struct {
+		Names []string `goquery:"h1, .upper,trim"`
+	}
+	if err := Unmarshal(loadString(t, unmarshalPage).Selection, &v); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(v.Names, "|") != "The Title|shout" {
+		t.Errorf("Names: got %v", v.Names)
+	}
+}
+
+func TestUnmarshalSlice(t *testing.T) {
+	var items []unmarshalItem
+	if err := Unmarshal(loadString(t, unmarshalPage).Find("li"), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[2].Name != "c" {
+		t.Errorf("expected 3 items, got %+v", items)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	sel := loadString(t, unmarshalPage).Selection
+
+	var v struct {
+		Title int `goquery:"h1"`
+	}
+	if err := Unmarshal(sel, v); err == nil {
+		t.Error("expected an error for a non-pointer")
+	}
+
+	err := Unmarshal(sel, &v)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) {
+		t.Fatalf("expected *UnmarshalError, got %T: %v", err, err)
+	}
+	if ue.Field != "Title" || ue.Selector != "h1" {
+		t.Errorf("expected error on field Title, got %+v", ue)
+	}
+
+	var items struct {
+		Items []struct {
+			Price int `goquery:".price"`
+		} `goquery:"li"`
+	}
+	if err := Unmarshal(sel, &items); !errors.As(err, &ue) || ue.Field != "Items[0].Price" {
+		t.Errorf("expected error on field Items[0].Price, got %v", err)
+	}
+
+	var invalid struct {
+		Title string `goquery:"~"`
+	}
+	var se *SelectorError
+	if err := Unmarshal(sel, &invalid); !errors.As(err, &se) {
+		t.Errorf("expected *SelectorError, got %v", err)
+	}
+
+	var unsupported struct {
+		Title map[string]string `goquery:"h1"`
+	}
+	if err := Unmarshal(sel, &unsupported); !errors.As(err, &ue) {
+		t.Errorf("expected *UnmarshalError, got %v", err)
+	}
+}
+
+// unmarshalTree is a recursive type, its Self field is filled from the same
+// element.
+type unmarshalTree struct {
+	Title string          `goquery:"h1"`
+	Self  *unmarshalTree  `goquery:""`
+	Items []unmarshalTree `goquery:"li"`
+}
+
+func TestUnmarshalRecursive(t *testing.T) {
+	sel := loadString(t, unmarshalPage).Selection
+
+	var v unmarshalTree
+	err := Unmarshal(sel, &v)
+	var ue *UnmarshalError
+	if !errors.As(err, &ue) || !errors.Is(err, errRecursiveStruct) {
+		t.Fatalf("expected an *UnmarshalError for the recursive struct, got %v", err)
+	}
+	if ue.Field != "Self" {
+		t.Errorf("expected error on field Self, got %+v", ue)
+	}
+
+	// with a non-empty selector, the recursion ends
+	var list struct {
+		Items []struct {
+			Name string          `goquery:""`
+			Sub  []unmarshalLeaf `goquery:"li"`
+		} `goquery:"li"`
+	}
+	if err := Unmarshal(sel, &list); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type unmarshalLeaf struct {
+	Name string `goquery:""`
+}
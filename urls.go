@@ -0,0 +1,209 @@
+package goquery
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// urlAttrs is the list of attributes that hold a single URL, rewritten by
+// Document.AbsolutizeURLs. The srcset attribute is handled separately since
+// it holds a list of URLs.
+var urlAttrs = []string{
+	"action",
+	"background",
+	"cite",
+	"codebase",
+	"formaction",
+	"href",
+	"longdesc",
+	"manifest",
+	"poster",
+	"src",
+}
+
+// elemURLAttrs is the list of attributes that hold a single URL only on
+// some elements, by attribute.
+var elemURLAttrs = map[string][]atom.Atom{
+	"data": {atom.Object},
+	"icon": {atom.Command, atom.Menuitem},
+}
+
+// BaseURL returns the URL used to resolve the relative URLs of the document:
+// the href of the first <base> element that has one, resolved against the
+// Document's Url, or the Document's Url if there is no such <base> element.
+// It returns nil if the base URL is unknown.
+func (d *Document) BaseURL() *url.URL {
+	base := d.FindFirstMatcher(baseHrefMatcher)
+	if base.Length() == 0 {
+		return d.Url
+	}
+
+	href, _ := base.Attr("href")
+	u, e := url.Parse(strings.TrimSpace(href))
+	if e != nil {
+		return d.Url
+	}
+	if d.Url != nil {
+		return d.Url.ResolveReference(u)
+	}
+	if u.IsAbs() {
+		return u
+	}
+	return nil
+}
+
+// AbsURL resolves the reference ref, typically the value of an href or src
+// attribute, against the document's base URL (see BaseURL). If the base URL
+// is unknown, ref is returned as-is, so it may still be relative.
+//
+// The base URL is looked up in the document on each call: to resolve many
+// references, call BaseURL once and resolve them with its ResolveReference
+// method.
+func (d *Document) AbsURL(ref string) (*url.URL, error) {
+	return resolveURL(d.BaseURL(), ref)
+}
+
+// AbsolutizeURLs rewrites in place the relative URLs of the document's
+// elements, resolving them against the document's base URL (see BaseURL).
+// The attributes rewritten are those that hold URLs, such as href, src,
+// srcset, action, formaction, poster, cite, background and the data of
+// <object> elements. Values that cannot be parsed as URLs are left
+// unchanged. It does nothing if the base URL is unknown.
+func (d *Document) AbsolutizeURLs() {
+	base := d.BaseURL()
+	if base == nil {
+		return
+	}
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, a := range n.Attr {
+				if a.Namespace != "" {
+					continue
+				}
+				if a.Key == "srcset" {
+					setAttr(d, n, a.Key, resolveSrcset(base, a.Val))
+				} else if isElementURLAttr(n, a.Key) {
+					if u, e := resolveURL(base, a.Val); e == nil {
+						setAttr(d, n, a.Key, u.String())
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(d.rootNode)
+}
+
+// AbsAttr gets the specified attribute's value for the first element in
+// the Selection, resolved as a URL against the document's base URL (see
+// Document.BaseURL). If the attribute's value cannot be parsed as a URL,
+// it is returned unchanged.
+func (s *Selection) AbsAttr(attrName string) (val string, exists bool) {
+	if val, exists = s.Attr(attrName); !exists || s.document == nil {
+		return
+	}
+	if u, e := resolveURL(s.document.BaseURL(), val); e == nil {
+		val = u.String()
+	}
+	return
+}
+
+// matches the first <base> element that sets the base URL.
+var baseHrefMatcher = cascadia.MustCompile("base[href]")
+
+func isURLAttr(key string) bool {
+	for _, k := range urlAttrs {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// isElementURLAttr returns true if the attribute key of the element n holds
+// a single URL, whether it does on all elements or only on some.
+func isElementURLAttr(n *html.Node, key string) bool {
+	if isURLAttr(key) {
+		return true
+	}
+	for _, a := range elemURLAttrs[key] {
+		if n.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveURL resolves ref against base, which may be nil.
+func resolveURL(base *url.URL, ref string) (*url.URL, error) {
+	u, e := url.Parse(strings.TrimSpace(ref))
+	if e != nil {
+		return nil, e
+	}
+	if base == nil {
+		return u, nil
+	}
+	return base.ResolveReference(u), nil
+}
+
+// resolveSrcset resolves each URL of the srcset attribute's value against
+// base, keeping the descriptors. It follows the parsing rules of the HTML
+// specification, so that URLs containing commas are supported.
+func resolveSrcset(base *url.URL, srcset string) string {
+	var out []string
+
+	s := srcset
+	for {
+		// skip leading whitespace and commas
+		s = strings.TrimLeft(s, " \t\n\f\r,")
+		if s == "" {
+			break
+		}
+
+		// the URL runs until the next whitespace
+		end := strings.IndexAny(s, " \t\n\f\r")
+		if end == -1 {
+			end = len(s)
+		}
+		ref := s[:end]
+		s = s[end:]
+
+		// trailing commas end the candidate, otherwise the descriptors run
+		// until the next comma outside of parentheses.
+		var desc string
+		if trimmed := strings.TrimRight(ref, ","); trimmed != ref {
+			ref = trimmed
+		} else {
+			depth := 0
+			i := 0
+			for ; i < len(s); i++ {
+				if s[i] == '(' {
+					depth++
+				} else if s[i] == ')' && depth > 0 {
+					depth--
+				} else if s[i] == ',' && depth == 0 {
+					break
+				}
+			}
+			desc, s = strings.TrimSpace(s[:i]), s[i:]
+		}
+
+		if u, e := resolveURL(base, ref); e == nil {
+			ref = u.String()
+		}
+		if desc != "" {
+			ref += " " + desc
+		}
+		out = append(out, ref)
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,148 @@
+package goquery
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func loadStringURL(t *testing.T, doc, u string) *Document {
+	d := loadString(t, doc)
+	if u != "" {
+		var err error
+		if d.Url, err = url.Parse(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return d
+}
+
+func TestBaseURL(t *testing.T) {
+	cases := []struct {
+		url  string
+		doc  string
+		want string
+	}{
+		0: {"", `<p>a</p>`, ""},
+		1: {"https://example.com/a/b.html", `<p>a</p>`, "https://example.com/a/b.html"},
+		2: {"https://example.com/a/b.html", `<head><base href="/c/"></head>`, "https://example.com/c/"},
+		3: {"https://example.com/a/b.html", `<head><base target="_top"><base href="../d/"><base href="/e/"></head>`, "https://example.com/d/"},
+		4: {"", `<head><base href="https://example.org/x/"></head>`, "https://example.org/x/"},
+		5: {"", `<head><base href="/relative/"></head>`, ""},
+	}
+	for i, c := range cases {
+		d := loadStringURL(t, c.doc, c.url)
+		got := d.BaseURL()
+		if c.want == "" {
+			if got != nil {
+				t.Errorf("[%d] expected nil base URL, got %s", i, got)
+			}
+			continue
+		}
+		if got == nil || got.String() != c.want {
+			t.Errorf("[%d] expected base URL %s, got %v", i, c.want, got)
+		}
+	}
+}
+
+func TestAbsAttr(t *testing.T) {
+	d := loadStringURL(t, `<head><base href="/docs/"></head><body>
+<a id="rel" href="page.html">a</a>
+<a id="root" href="/index.html">b</a>
+<a id="abs" href="http://other.org/x">c</a>
+<a id="frag" href="#top">d</a>
+<a id="mail" href="mailto:me@example.com">e</a>
+<img src=" img/a.png ">
+</body>`, "https://example.com/a/b.html")
+
+	cases := []struct {
+		sel, attr, want string
+		exists          bool
+	}{
+		{"#rel", "href", "https://example.com/docs/page.html", true},
+		{"#root", "href", "https://example.com/index.html", true},
+		{"#abs", "href", "http://other.org/x", true},
+		{"#frag", "href", "https://example.com/docs/#top", true},
+		{"#mail", "href", "mailto:me@example.com", true},
+		{"img", "src", "https://example.com/docs/img/a.png", true},
+		{"img", "href", "", false},
+		{"zzz", "href", "", false},
+	}
+	for i, c := range cases {
+		got, ok := d.Find(c.sel).AbsAttr(c.attr)
+		if ok != c.exists || got != c.want {
+			t.Errorf("[%d] expected %q (%t), got %q (%t)", i, c.want, c.exists, got, ok)
+		}
+	}
+}
+
+func TestAbsAttrNoBase(t *testing.T) {
+	d := loadString(t, `<a href="page.html">a</a>`)
+	if got, _ := d.Find("a").AbsAttr("href"); got != "page.html" {
+		t.Errorf("expected unchanged URL, got %q", got)
+	}
+}
+
+func TestAbsURL(t *testing.T) {
+	d := loadStringURL(t, `<p>a</p>`, "https://example.com/a/b.html")
+	u, err := d.AbsURL("../c?d=e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != "https://example.com/c?d=e" {
+		t.Errorf("expected https://example.com/c?d=e, got %s", got)
+	}
+	if _, err := d.AbsURL("%zz"); err == nil {
+		t.Error("expected an error for an invalid URL")
+	}
+}
+
+func TestAbsolutizeURLs(t *testing.T) {
+	d := loadStringURL(t, `<body>
+<a href="x.html">a</a>
+<form action="post"><button formaction="/submit"></button></form>
+<video poster="p.jpg"><source src="v.mp4"></video>
+<blockquote cite="q.html"></blockquote>
+<object data="o.swf"></object>
+<img src="a.png" srcset="a-1x.png 1x, a-2x.png 2x,b.png, data:image/png;base64,AA== 100w, c.png (max-width: 10px, 5px) 3x">
+<a href="%zz">invalid</a>
+<div title="t.html" data="d.html" icon="i.png"></div>
+</body>`, "https://example.com/dir/index.html")
+
+	d.AbsolutizeURLs()
+
+	cases := []struct {
+		sel, attr, want string
+	}{
+		{"a", "href", "https://example.com/dir/x.html"},
+		{"form", "action", "https://example.com/dir/post"},
+		{"button", "formaction", "https://example.com/submit"},
+		{"video", "poster", "https://example.com/dir/p.jpg"},
+		{"source", "src", "https://example.com/dir/v.mp4"},
+		{"blockquote", "cite", "https://example.com/dir/q.html"},
+		{"object", "data", "https://example.com/dir/o.swf"},
+		{"img", "src", "https://example.com/dir/a.png"},
+		{"img", "srcset", "https://example.com/dir/a-1x.png 1x, https://example.com/dir/a-2x.png 2x, https://example.com/dir/b.png, data:image/png;base64,AA== 100w, https://example.com/dir/c.png (max-width: 10px, 5px) 3x"},
+		{"a:contains(invalid)", "href", "%zz"},
+		{"div", "title", "t.html"},
+		{"div", "data", "d.html"},
+		{"div", "icon", "i.png"},
+	}
+	for i, c := range cases {
+		if got, _ := d.Find(c.sel).Attr(c.attr); got != c.want {
+			t.Errorf("[%d] %s[%s]: expected %q, got %q", i, c.sel, c.attr, c.want, got)
+		}
+	}
+}
+
+func TestAbsolutizeURLsNoBase(t *testing.T) {
+	src := `<a href="x.html">a</a>`
+	d := loadString(t, src)
+	d.AbsolutizeURLs()
+	if got, _ := d.Find("a").Attr("href"); got != "x.html" {
+		t.Errorf("expected unchanged URL, got %q", got)
+	}
+	if h, _ := OuterHtml(d.Find("a")); !strings.Contains(h, `href="x.html"`) {
+		t.Errorf("expected unchanged HTML, got %s", h)
+	}
+}